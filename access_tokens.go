@@ -0,0 +1,40 @@
+package main
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+// accessTokenDuration is how long an access token is valid. Clients get a
+// new one from POST /api/refresh.
+const accessTokenDuration = time.Hour
+
+// accessTokenScopes are granted to every access token from a login. They
+// tell other services verifying our tokens what the bearer may do.
+var accessTokenScopes = []string{"chirps:read", "chirps:write", "account"}
+
+// makeAccessToken issues an access token for user in a session, see
+// refreshSession. It also returns the token's ID, which is saved with the
+// session's refresh token so logging out can revoke the access token too.
+func (cfg *apiConfig) makeAccessToken(user database.User, sessionID uuid.UUID) (string, string, error) {
+	tier := auth.TierFree
+	if user.IsChirpyRed {
+		tier = auth.TierChirpyRed
+	}
+	claims := auth.Claims{
+		UserID:    user.ID,
+		SessionID: sessionID,
+		Tier:      tier,
+		Roles:     user.Roles,
+		Scopes:    accessTokenScopes,
+	}
+	claims.ID = uuid.NewString()
+	token, err := cfg.keyring.MakeJWT(claims, accessTokenDuration)
+	if err != nil {
+		return "", "", err
+	}
+	return token, claims.ID, nil
+}
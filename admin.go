@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gooneraki/chirpy-go/internal/auth"
+)
+
+// adminRole is the role in users.roles that grants access to the admin API.
+const adminRole = "admin"
+
+// requireAdmin lets through callers with the ADMIN_API_KEY, sent as
+// "Authorization: ApiKey <key>", and users whose access token has the admin
+// role. It writes the error response itself and reports whether the handler
+// should continue.
+func (cfg *apiConfig) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if token, err := auth.GetBearerToken(r.Header); err == nil {
+		claims, err := cfg.validateJWT(r.Context(), token)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+			return false
+		}
+		if !claims.HasRole(adminRole) {
+			respondWithError(w, http.StatusForbidden, "Admin role required", nil)
+			return false
+		}
+		return true
+	}
+
+	if cfg.adminKey == "" {
+		respondWithError(w, http.StatusForbidden, "Admin API is disabled", errors.New("ADMIN_API_KEY is not set"))
+		return false
+	}
+
+	apiKey, err := auth.GetAPIKey(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find api key", err)
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey), []byte(cfg.adminKey)) != 1 {
+		respondWithError(w, http.StatusUnauthorized, "API key is invalid", nil)
+		return false
+	}
+	return true
+}
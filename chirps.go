@@ -1,28 +1,95 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/chirptext"
 	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/moderation"
 )
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    uuid.UUID `json:"user_id"`
-	Body      string    `json:"body"`
+	ID              uuid.UUID          `json:"id"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	UserID          uuid.UUID          `json:"user_id"`
+	Body            string             `json:"body"`
+	Kind            database.ChirpKind `json:"kind"`
+	InReplyToID     uuid.NullUUID      `json:"in_reply_to_id"`
+	OriginalChirpID uuid.NullUUID      `json:"original_chirp_id"`
+	// OriginalChirp is nil when OriginalChirpID points at a deleted chirp.
+	OriginalChirp *Chirp        `json:"original_chirp"`
+	Author        *Profile      `json:"author"`
+	Media         []Media       `json:"media"`
+	Entities      ChirpEntities `json:"entities"`
+	ReplyCount    int32         `json:"reply_count"`
+	LikeCount     int32         `json:"like_count"`
+	RechirpCount  int32         `json:"rechirp_count"`
+	QuoteCount    int32         `json:"quote_count"`
+	LikedByMe     bool          `json:"liked_by_me"`
+	Edited        bool          `json:"edited"`
+}
+
+func databaseChirpToChirp(dbChirp database.Chirp) Chirp {
+	return Chirp{
+		ID:              dbChirp.ID,
+		CreatedAt:       dbChirp.CreatedAt,
+		UpdatedAt:       dbChirp.UpdatedAt,
+		UserID:          dbChirp.UserID,
+		Body:            dbChirp.Body,
+		Kind:            dbChirp.Kind,
+		InReplyToID:     dbChirp.InReplyToID,
+		OriginalChirpID: dbChirp.OriginalChirpID,
+		Media:           []Media{},
+		Entities:        ChirpEntities{Mentions: []MentionEntity{}, Hashtags: []HashtagEntity{}},
+		ReplyCount:      dbChirp.ReplyCount,
+		LikeCount:       dbChirp.LikeCount,
+		RechirpCount:    dbChirp.RechirpCount,
+		QuoteCount:      dbChirp.QuoteCount,
+		// Only edits touch updated_at after creation, see handlerChirpsUpdate.
+		Edited: dbChirp.UpdatedAt.After(dbChirp.CreatedAt),
+	}
+}
+
+// hydrateChirps fills in the parts of a chirp response that don't live on
+// the chirp's own row: the chirp it rechirps or quotes, its author's
+// profile, attached media, mentions and hashtags and whether the viewer has
+// liked it.
+func (cfg *apiConfig) hydrateChirps(ctx context.Context, viewerID uuid.NullUUID, chirps ...*Chirp) error {
+	originals, err := cfg.embedOriginalChirps(ctx, chirps...)
+	if err != nil {
+		return err
+	}
+	all := append(chirps, originals...)
+	err = cfg.setChirpAuthors(ctx, all...)
+	if err != nil {
+		return err
+	}
+	err = cfg.setChirpMedia(ctx, all...)
+	if err != nil {
+		return err
+	}
+	err = cfg.setChirpEntities(ctx, all...)
+	if err != nil {
+		return err
+	}
+	return cfg.setLikedByMe(ctx, viewerID, all...)
 }
 
 func (cfg *apiConfig) handlerChirpsCreate(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
-		Body string `json:"body"`
+		Body        string        `json:"body"`
+		InReplyToID uuid.NullUUID `json:"in_reply_to_id"`
+		QuoteOfID   uuid.NullUUID `json:"quote_of_id"`
+		MediaIDs    []uuid.UUID   `json:"media_ids"`
 	}
 
 	token, err := auth.GetBearerToken(r.Header)
@@ -30,67 +97,236 @@ func (cfg *apiConfig) handlerChirpsCreate(w http.ResponseWriter, r *http.Request
 		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
 		return
 	}
-	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	claims, err := cfg.validateJWT(r.Context(), token)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
 		return
 	}
+	userID := claims.UserID
 
+	r.Body = http.MaxBytesReader(w, r.Body, maxChirpRequestSize)
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
 	err = decoder.Decode(&params)
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Request body is too large", err)
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
 		return
 	}
 
-	cleaned, err := validateChirp(params.Body)
+	author, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	if !author.EmailVerifiedAt.Valid {
+		respondWithError(w, http.StatusForbidden, "Verify your email address before posting", nil)
+		return
+	}
+
+	moderated, err := cfg.validateChirp(r.Context(), params.Body, author)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		respondWithChirpValidationError(w, err)
 		return
 	}
 
-	chirp, err := cfg.db.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   cleaned,
-		UserID: userID,
-	})
+	err = cfg.checkAttachableMedia(r.Context(), userID, params.MediaIDs)
+	if err != nil {
+		var validationErr chirpValidationError
+		if errors.As(err, &validationErr) {
+			respondWithError(w, http.StatusBadRequest, validationErr.Message, err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get media", err)
+		return
+	}
+
+	if params.InReplyToID.Valid {
+		_, err = cfg.db.GetChirp(r.Context(), params.InReplyToID.UUID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "Couldn't find chirp to reply to", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp to reply to", err)
+			return
+		}
+	}
+
+	kind := database.ChirpKindChirp
+	originalChirpID := uuid.NullUUID{}
+	if params.QuoteOfID.Valid {
+		original, err := cfg.resolveOriginalChirp(r.Context(), params.QuoteOfID.UUID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "Couldn't find chirp to quote", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp to quote", err)
+			return
+		}
+		kind = database.ChirpKindQuote
+		originalChirpID = uuid.NullUUID{UUID: original.ID, Valid: true}
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
 
-	respondWithJSON(w, http.StatusCreated, Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
+	chirp, err := qtx.CreateChirp(r.Context(), database.CreateChirpParams{
+		Body:            moderated.Body,
+		UserID:          userID,
+		InReplyToID:     params.InReplyToID,
+		Kind:            kind,
+		OriginalChirpID: originalChirpID,
 	})
-}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		return
+	}
 
-func validateChirp(body string) (string, error) {
-	const maxChirpLength = 140
-	if len(body) > maxChirpLength {
-		return "", errors.New("Chirp is too long")
+	if chirp.InReplyToID.Valid {
+		err = qtx.IncrementReplyCount(r.Context(), chirp.InReplyToID.UUID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update reply count", err)
+			return
+		}
 	}
 
-	badWords := map[string]struct{}{
-		"kerfuffle": {},
-		"sharbert":  {},
-		"fornax":    {},
+	for i, mediaID := range params.MediaIDs {
+		err = qtx.AttachMedia(r.Context(), database.AttachMediaParams{
+			ChirpID:  chirp.ID,
+			MediaID:  mediaID,
+			Position: int32(i),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't attach media", err)
+			return
+		}
 	}
-	cleaned := getCleanedBody(body, badWords)
-	return cleaned, nil
+
+	err = recordModerationFlags(r.Context(), qtx, chirp.ID, moderated)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't flag chirp for review", err)
+		return
+	}
+
+	err = saveChirpEntities(r.Context(), qtx, chirp.ID, moderated)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save mentions and hashtags", err)
+		return
+	}
+
+	if chirp.OriginalChirpID.Valid {
+		err = qtx.IncrementQuoteCount(r.Context(), chirp.OriginalChirpID.UUID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update quote count", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		return
+	}
+
+	resp := databaseChirpToChirp(chirp)
+	err = cfg.hydrateChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, &resp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get quoted chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+const (
+	maxChirpLength          = 140
+	maxChirpyRedChirpLength = 500
+	// maxBytesPerCharacter bounds the size of text whose length is counted
+	// in characters. Without it a single URL, or a letter followed by
+	// thousands of combining marks, would count as one short chirp.
+	maxBytesPerCharacter = 4
+	// maxChirpRequestSize leaves room for a chirp at the Chirpy Red limit
+	// with every character escaped in JSON, plus the other parameters.
+	maxChirpRequestSize = 64 << 10
+)
+
+// chirpValidationError is a problem with a chirp body that its author has
+// to fix, as opposed to a failure on our side.
+type chirpValidationError struct {
+	Message string
+	// Limit and Length are set when the chirp is too long.
+	Limit  int
+	Length int
+}
+
+func (e chirpValidationError) Error() string {
+	return e.Message
 }
 
-func getCleanedBody(body string, badWords map[string]struct{}) string {
-	words := strings.Split(body, " ")
-	for i, word := range words {
-		loweredWord := strings.ToLower(word)
-		if _, ok := badWords[loweredWord]; ok {
-			words[i] = "****"
+// validateChirp checks a chirp body written by author against the length
+// limit of their tier and the moderation rules. Length is counted in
+// characters as users see them, see chirptext.Length, and the body can't
+// take more than maxBytesPerCharacter bytes for each of them either.
+func (cfg *apiConfig) validateChirp(ctx context.Context, body string, author database.User) (moderation.Result, error) {
+	limit := maxChirpLength
+	if author.IsChirpyRed {
+		limit = maxChirpyRedChirpLength
+	}
+	if len(body) > maxBytesPerCharacter*limit {
+		return moderation.Result{}, chirpValidationError{
+			Message: fmt.Sprintf("Chirp is too long: %d bytes, the limit is %d", len(body), maxBytesPerCharacter*limit),
+		}
+	}
+	length := chirptext.Length(body)
+	if length > limit {
+		return moderation.Result{}, chirpValidationError{
+			Message: fmt.Sprintf("Chirp is too long: %d characters, the limit is %d", length, limit),
+			Limit:   limit,
+			Length:  length,
 		}
 	}
-	cleaned := strings.Join(words, " ")
-	return cleaned
+
+	result, err := cfg.moderator.Moderate(ctx, body)
+	if err != nil {
+		return moderation.Result{}, err
+	}
+	if result.Rejected() {
+		return moderation.Result{}, chirpValidationError{Message: "Chirp violates the content policy"}
+	}
+	return result, nil
+}
+
+// respondWithChirpValidationError reports an error from validateChirp.
+func respondWithChirpValidationError(w http.ResponseWriter, err error) {
+	type tooLongResponse struct {
+		Error  string `json:"error"`
+		Limit  int    `json:"limit"`
+		Length int    `json:"length"`
+	}
+
+	var validationErr chirpValidationError
+	if !errors.As(err, &validationErr) {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't moderate chirp", err)
+		return
+	}
+	if validationErr.Limit > 0 {
+		respondWithJSON(w, http.StatusBadRequest, tooLongResponse{
+			Error:  validationErr.Message,
+			Limit:  validationErr.Limit,
+			Length: validationErr.Length,
+		})
+		return
+	}
+	respondWithError(w, http.StatusBadRequest, validationErr.Message, err)
 }
@@ -0,0 +1,36 @@
+// Command argon2tune suggests Argon2id parameters that take about a target
+// time to hash a password on the machine it runs on, printed as the
+// environment variables the server reads.
+//
+//	go run ./cmd/argon2tune -target 250ms -memory 65536 -parallelism 4
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gooneraki/chirpy-go/internal/auth"
+)
+
+func main() {
+	target := flag.Duration("target", 250*time.Millisecond, "how long hashing a password should take")
+	memory := flag.Uint("memory", uint(auth.DefaultPasswordHashParams.Memory), "most memory to use, in KiB")
+	parallelism := flag.Uint("parallelism", uint(auth.DefaultPasswordHashParams.Parallelism), "number of lanes")
+	flag.Parse()
+
+	if *parallelism < 1 || *parallelism > 255 {
+		log.Fatal("parallelism must be between 1 and 255")
+	}
+
+	params, elapsed, err := auth.TunePasswordHashParams(*target, uint32(*memory), uint8(*parallelism))
+	if err != nil {
+		log.Fatalf("Error tuning Argon2 parameters: %s", err)
+	}
+
+	fmt.Printf("# Hashing takes %s on this machine\n", elapsed.Round(time.Millisecond))
+	fmt.Printf("ARGON2_MEMORY=%d\n", params.Memory)
+	fmt.Printf("ARGON2_ITERATIONS=%d\n", params.Iterations)
+	fmt.Printf("ARGON2_PARALLELISM=%d\n", params.Parallelism)
+}
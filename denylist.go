@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+// denylistPruneInterval is how often expired entries are removed from the
+// access token denylist.
+const denylistPruneInterval = 10 * time.Minute
+
+// dbDenylist keeps revoked access token IDs in Postgres, so every instance
+// of the server sees them.
+type dbDenylist struct {
+	db *database.Queries
+}
+
+func (d dbDenylist) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return d.db.RevokeAccessToken(ctx, database.RevokeAccessTokenParams{
+		Jti:       tokenID,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (d dbDenylist) Contains(ctx context.Context, tokenID string) (bool, error) {
+	return d.db.IsAccessTokenRevoked(ctx, tokenID)
+}
+
+func (d dbDenylist) Prune(ctx context.Context) error {
+	_, err := d.db.PruneRevokedAccessTokens(ctx)
+	return err
+}
+
+// validateJWT validates an access token and makes sure it hasn't been
+// revoked.
+func (cfg *apiConfig) validateJWT(ctx context.Context, token string) (auth.Claims, error) {
+	claims, err := cfg.keyring.ValidateJWT(token)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	revoked, err := cfg.denylist.Contains(ctx, claims.ID)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	if revoked {
+		return auth.Claims{}, auth.ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// denySessionAccessTokens revokes the access tokens of a session that has
+// been logged out and might not have expired yet.
+func (cfg *apiConfig) denySessionAccessTokens(ctx context.Context, familyID uuid.UUID) error {
+	tokens, err := cfg.db.GetSessionAccessTokens(ctx, database.GetSessionAccessTokensParams{
+		FamilyID:    familyID,
+		IssuedAfter: time.Now().UTC().Add(-accessTokenDuration),
+	})
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		err = cfg.denyAccessToken(ctx, token.Jti, token.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// denyUserAccessTokens revokes the access tokens of all of a user's
+// sessions, except the one with exceptFamilyID if it is set.
+func (cfg *apiConfig) denyUserAccessTokens(ctx context.Context, userID uuid.UUID, exceptFamilyID uuid.NullUUID) error {
+	tokens, err := cfg.db.GetUserAccessTokens(ctx, database.GetUserAccessTokensParams{
+		UserID:         userID,
+		IssuedAfter:    time.Now().UTC().Add(-accessTokenDuration),
+		ExceptFamilyID: exceptFamilyID,
+	})
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		err = cfg.denyAccessToken(ctx, token.Jti, token.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// denyAccessToken adds the access token issued at issuedAt to the denylist
+// until it expires, with a minute to spare for clock differences.
+func (cfg *apiConfig) denyAccessToken(ctx context.Context, tokenID string, issuedAt time.Time) error {
+	return cfg.denylist.Add(ctx, tokenID, issuedAt.Add(accessTokenDuration+time.Minute))
+}
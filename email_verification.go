@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/mail"
+)
+
+const (
+	// emailVerificationTokenDuration is how long a verification link works.
+	emailVerificationTokenDuration = 24 * time.Hour
+	// maxEmailVerificationRequests limits how many verification emails a
+	// user can be sent in emailVerificationRequestWindow.
+	maxEmailVerificationRequests   = 3
+	emailVerificationRequestWindow = 15 * time.Minute
+)
+
+// errTooManyVerificationEmails -
+var errTooManyVerificationEmails = fmt.Errorf("too many verification emails, try again in %s", emailVerificationRequestWindow)
+
+// sendEmailVerification emails a link to address that proves it belongs to
+// the user. Verifying it makes address the user's email, so the same link
+// confirms a new account and an email change.
+func (cfg *apiConfig) sendEmailVerification(ctx context.Context, userID uuid.UUID, address string) error {
+	verificationToken, err := createEmailVerificationToken(ctx, cfg.db, userID, address)
+	if err != nil {
+		return err
+	}
+	cfg.mailEmailVerification(ctx, address, verificationToken)
+	return nil
+}
+
+// createEmailVerificationToken saves a new verification token for address
+// unless the user has been sent too many lately. It takes the queries to run
+// so it can be part of a larger transaction, with the email sent by
+// mailEmailVerification once that commits.
+func createEmailVerificationToken(ctx context.Context, q *database.Queries, userID uuid.UUID, address string) (string, error) {
+	recentRequests, err := q.CountRecentEmailVerificationTokens(ctx, database.CountRecentEmailVerificationTokensParams{
+		UserID: userID,
+		Since:  time.Now().UTC().Add(-emailVerificationRequestWindow),
+	})
+	if err != nil {
+		return "", err
+	}
+	if recentRequests >= maxEmailVerificationRequests {
+		return "", errTooManyVerificationEmails
+	}
+
+	// Like reset tokens, verification tokens are made and stored like
+	// refresh tokens.
+	verificationToken := auth.MakeRefreshToken()
+	err = q.CreateEmailVerificationToken(ctx, database.CreateEmailVerificationTokenParams{
+		TokenHash: auth.HashRefreshToken(verificationToken),
+		UserID:    userID,
+		Email:     address,
+		ExpiresAt: time.Now().UTC().Add(emailVerificationTokenDuration),
+	})
+	if err != nil {
+		return "", err
+	}
+	return verificationToken, nil
+}
+
+// mailEmailVerification sends the link for a token from
+// createEmailVerificationToken.
+func (cfg *apiConfig) mailEmailVerification(ctx context.Context, address, verificationToken string) {
+	cfg.sendMail(ctx, mail.Message{
+		To:      address,
+		Subject: "Verify your Chirpy email address",
+		Body: fmt.Sprintf(
+			"Confirm that this is the email address of your Chirpy account:\n\n%s\n\nThe link expires in %s. If you didn't sign up for Chirpy or change your email, you can ignore this email.\n",
+			cfg.appLink("/verify-email", verificationToken),
+			emailVerificationTokenDuration,
+		),
+	})
+}
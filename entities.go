@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/chirptext"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/moderation"
+)
+
+// ChirpEntities are the parts of a chirp's body that clients can link.
+// Indices are the [start, end) offsets of the entity, @ or # included, in
+// Unicode code points of the body.
+type ChirpEntities struct {
+	Mentions []MentionEntity `json:"mentions"`
+	Hashtags []HashtagEntity `json:"hashtags"`
+}
+
+type MentionEntity struct {
+	UserID uuid.UUID `json:"user_id"`
+	// Handle is as written in the chirp, which may no longer be the user's.
+	Handle  string `json:"handle"`
+	Indices [2]int `json:"indices"`
+}
+
+type HashtagEntity struct {
+	Tag     string `json:"tag"`
+	Indices [2]int `json:"indices"`
+}
+
+// saveChirpEntities records the mentions and hashtags in a moderated chirp
+// body, replacing the ones of the chirp's previous body. Mentions of handles
+// nobody has are left out.
+func saveChirpEntities(ctx context.Context, qtx *database.Queries, chirpID uuid.UUID, result moderation.Result) error {
+	err := qtx.DeleteChirpMentions(ctx, chirpID)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteChirpHashtags(ctx, chirpID)
+	if err != nil {
+		return err
+	}
+
+	entities := chirptext.Entities(result.Body, result.Masked)
+	handles := []string{}
+	for _, entity := range entities {
+		if entity.Kind == chirptext.EntityMention {
+			handles = append(handles, strings.ToLower(entity.Text))
+		}
+	}
+	userIDs := map[string]uuid.UUID{}
+	if len(handles) > 0 {
+		users, err := qtx.GetUsersByHandles(ctx, handles)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			userIDs[strings.ToLower(user.Handle)] = user.ID
+		}
+	}
+
+	for _, entity := range entities {
+		start := int32(utf8.RuneCountInString(result.Body[:entity.Start]))
+		end := start + int32(utf8.RuneCountInString(result.Body[entity.Start:entity.End]))
+		switch entity.Kind {
+		case chirptext.EntityMention:
+			userID, ok := userIDs[strings.ToLower(entity.Text)]
+			if !ok {
+				continue
+			}
+			err = qtx.CreateChirpMention(ctx, database.CreateChirpMentionParams{
+				ChirpID:    chirpID,
+				UserID:     userID,
+				Handle:     entity.Text,
+				StartIndex: start,
+				EndIndex:   end,
+			})
+		case chirptext.EntityHashtag:
+			tag, _ := chirptext.ParseHashtag(entity.Text)
+			err = qtx.CreateChirpHashtag(ctx, database.CreateChirpHashtagParams{
+				ChirpID:    chirpID,
+				Tag:        tag,
+				StartIndex: start,
+				EndIndex:   end,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setChirpEntities fills in the mentions and hashtags of the given chirps
+// with one query for each.
+func (cfg *apiConfig) setChirpEntities(ctx context.Context, chirps ...*Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+	mentions, err := cfg.db.GetChirpMentions(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+	hashtags, err := cfg.db.GetChirpHashtags(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+
+	byChirp := make(map[uuid.UUID]ChirpEntities, len(chirps))
+	for _, mention := range mentions {
+		entities := byChirp[mention.ChirpID]
+		entities.Mentions = append(entities.Mentions, MentionEntity{
+			UserID:  mention.UserID,
+			Handle:  mention.Handle,
+			Indices: [2]int{int(mention.StartIndex), int(mention.EndIndex)},
+		})
+		byChirp[mention.ChirpID] = entities
+	}
+	for _, hashtag := range hashtags {
+		entities := byChirp[hashtag.ChirpID]
+		entities.Hashtags = append(entities.Hashtags, HashtagEntity{
+			Tag:     hashtag.Tag,
+			Indices: [2]int{int(hashtag.StartIndex), int(hashtag.EndIndex)},
+		})
+		byChirp[hashtag.ChirpID] = entities
+	}
+	for _, chirp := range chirps {
+		entities := byChirp[chirp.ID]
+		if entities.Mentions != nil {
+			chirp.Entities.Mentions = entities.Mentions
+		}
+		if entities.Hashtags != nil {
+			chirp.Entities.Hashtags = entities.Hashtags
+		}
+	}
+	return nil
+}
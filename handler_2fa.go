@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+const (
+	// totpIssuer is how Chirpy is labelled in authenticator apps.
+	totpIssuer = "Chirpy"
+	// recoveryCodeCount is how many recovery codes users get when they
+	// enable two-factor authentication.
+	recoveryCodeCount = 10
+)
+
+func (cfg *apiConfig) handlerTOTPEnroll(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Secret     string `json:"secret"`
+		OTPAuthURI string `json:"otpauth_uri"`
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't generate secret", err)
+		return
+	}
+	err = cfg.db.SetPendingTOTPSecret(r.Context(), database.SetPendingTOTPSecretParams{
+		ID:         userID,
+		TotpSecret: secret,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save secret", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(secret, totpIssuer, user.Email),
+	})
+}
+
+func (cfg *apiConfig) handlerTOTPConfirm(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Code string `json:"code"`
+	}
+	type response struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+	if !user.TotpSecret.Valid {
+		respondWithError(w, http.StatusBadRequest, "Enroll in two-factor authentication first", nil)
+		return
+	}
+
+	step, ok, err := auth.ValidateTOTP(user.TotpSecret.String, params.Code, time.Now())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check code", err)
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid code", nil)
+		return
+	}
+
+	recoveryCodes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't generate recovery codes", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't enable two-factor authentication", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.EnableTOTP(r.Context(), database.EnableTOTPParams{
+		ID:           userID,
+		TotpLastStep: step,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't enable two-factor authentication", err)
+		return
+	}
+	err = qtx.DeleteRecoveryCodes(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save recovery codes", err)
+		return
+	}
+	for _, code := range recoveryCodes {
+		err = qtx.CreateRecoveryCode(r.Context(), database.CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: auth.HashRecoveryCode(code),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't save recovery codes", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't enable two-factor authentication", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+func (cfg *apiConfig) handlerTOTPDisable(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusConflict, "Two-factor authentication isn't enabled", nil)
+		return
+	}
+
+	if !cfg.verifySecondFactor(w, r, user, params.Code, params.RecoveryCode) {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't disable two-factor authentication", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.DisableTOTP(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't disable two-factor authentication", err)
+		return
+	}
+	err = qtx.DeleteRecoveryCodes(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete recovery codes", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't disable two-factor authentication", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerLogin2FA finishes a login that handlerLogin answered with an MFA
+// challenge.
+func (cfg *apiConfig) handlerLogin2FA(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		MFAToken     string `json:"mfa_token"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	userID, err := cfg.keyring.ValidateMFAChallenge(params.MFAToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate MFA token", err)
+		return
+	}
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusUnauthorized, "Couldn't validate MFA token", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusUnauthorized, "Two-factor authentication isn't enabled", nil)
+		return
+	}
+
+	if !cfg.verifySecondFactor(w, r, user, params.Code, params.RecoveryCode) {
+		return
+	}
+
+	cfg.logIn(w, r, user)
+}
+
+// verifySecondFactor checks a TOTP or recovery code for user. Wrong codes
+// count against the account like wrong passwords, so codes can't be guessed
+// either, not even by someone holding a stolen access token. It writes the
+// error response itself and reports whether the handler should continue.
+func (cfg *apiConfig) verifySecondFactor(w http.ResponseWriter, r *http.Request, user database.User, code, recoveryCode string) bool {
+	attempt, ok := cfg.startLoginAttempt(w, r, user.Email)
+	if !ok {
+		return false
+	}
+
+	ok, err := cfg.checkSecondFactor(r.Context(), user, code, recoveryCode)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check code", err)
+		return false
+	}
+	if !ok {
+		err = cfg.failLoginAttempt(r.Context(), attempt, uuid.NullUUID{UUID: user.ID, Valid: true})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't record failed login", err)
+			return false
+		}
+		respondWithError(w, http.StatusUnauthorized, "Invalid code", nil)
+		return false
+	}
+
+	err = cfg.succeedLoginAttempt(r.Context(), attempt)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record login attempt", err)
+		return false
+	}
+	return true
+}
+
+// checkSecondFactor accepts either a TOTP code, each of which works only
+// once, or an unused recovery code, which is used up.
+func (cfg *apiConfig) checkSecondFactor(ctx context.Context, user database.User, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		used, err := cfg.db.UseRecoveryCode(ctx, database.UseRecoveryCodeParams{
+			UserID:   user.ID,
+			CodeHash: auth.HashRecoveryCode(recoveryCode),
+		})
+		return used > 0, err
+	}
+
+	step, ok, err := auth.ValidateTOTP(user.TotpSecret.String, code, time.Now())
+	if err != nil || !ok {
+		return false, err
+	}
+	// Only a step after the last one used is accepted, so a code someone
+	// has seen can't be replayed.
+	used, err := cfg.db.UseTOTPStep(ctx, database.UseTOTPStepParams{
+		ID:   user.ID,
+		Step: step,
+	})
+	return used > 0, err
+}
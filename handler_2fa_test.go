@@ -0,0 +1,85 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/throttle"
+)
+
+func TestVerifySecondFactorThrottlesWrongCodes(t *testing.T) {
+	cfg := &apiConfig{loginFailures: throttle.NewMemoryStore()}
+	user := database.User{
+		ID:            uuid.New(),
+		Email:         "jane@example.com",
+		TotpSecret:    sql.NullString{String: "JBSWY3DPEHPK3PXP", Valid: true},
+		TotpEnabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	// A malformed code is wrong without touching the database.
+	attempt := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/api/users/2fa", nil)
+		if cfg.verifySecondFactor(w, r, user, "12345", "") {
+			t.Fatal("verifySecondFactor() accepted a wrong code")
+		}
+		return w
+	}
+
+	for i := range accountLoginPolicy.FreeFailures + 1 {
+		if w := attempt(); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want %d", i+1, w.Code, http.StatusUnauthorized)
+		}
+	}
+	w := attempt()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("throttled attempt: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("throttled attempt: missing Retry-After")
+	}
+}
+
+func TestVerifySecondFactorThrottlesParallelCodes(t *testing.T) {
+	cfg := &apiConfig{loginFailures: throttle.NewMemoryStore()}
+	user := database.User{
+		ID:            uuid.New(),
+		Email:         "jane@example.com",
+		TotpSecret:    sql.NullString{String: "JBSWY3DPEHPK3PXP", Valid: true},
+		TotpEnabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	const attempts = 10
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/login/2fa", nil)
+			cfg.verifySecondFactor(w, r, user, "12345", "")
+			codes <- w.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	// Every guess is counted before it is checked, so only the free ones
+	// and the first after them get through, however many run at once.
+	checked := 0
+	for code := range codes {
+		if code == http.StatusUnauthorized {
+			checked++
+		}
+	}
+	if want := accountLoginPolicy.FreeFailures + 1; checked != want {
+		t.Errorf("%d of %d parallel guesses were checked, want %d", checked, attempts, want)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+type LoginLockout struct {
+	ID          uuid.UUID     `json:"id"`
+	Email       string        `json:"email"`
+	UserID      uuid.NullUUID `json:"user_id"`
+	IPAddress   string        `json:"ip_address"`
+	Failures    int32         `json:"failures"`
+	LockedUntil time.Time     `json:"locked_until"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerLoginLockoutsGet(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Lockouts   []LoginLockout `json:"lockouts"`
+		NextCursor string         `json:"next_cursor,omitempty"`
+	}
+
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	afterCreatedAt, afterID := cursorParams(cursor)
+
+	dbLockouts, err := cfg.db.GetUnreviewedLoginLockouts(r.Context(), database.GetUnreviewedLoginLockoutsParams{
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve login lockouts", err)
+		return
+	}
+
+	resp := response{
+		Lockouts: []LoginLockout{},
+	}
+	if len(dbLockouts) > limit {
+		dbLockouts = dbLockouts[:limit]
+		last := dbLockouts[len(dbLockouts)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	for _, dbLockout := range dbLockouts {
+		resp.Lockouts = append(resp.Lockouts, LoginLockout{
+			ID:          dbLockout.ID,
+			Email:       dbLockout.Email,
+			UserID:      dbLockout.UserID,
+			IPAddress:   dbLockout.IpAddress,
+			Failures:    dbLockout.Failures,
+			LockedUntil: dbLockout.LockedUntil,
+			CreatedAt:   dbLockout.CreatedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handlerLoginLockoutsReview(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	lockoutIDString := r.PathValue("lockoutID")
+	lockoutID, err := uuid.Parse(lockoutIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid lockout ID", err)
+		return
+	}
+
+	_, err = cfg.db.ReviewLoginLockout(r.Context(), lockoutID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find login lockout", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't review login lockout", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
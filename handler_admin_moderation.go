@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/moderation"
+)
+
+type ModerationWord struct {
+	Word      string            `json:"word"`
+	Action    moderation.Action `json:"action"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type ModerationFlag struct {
+	ID        uuid.UUID `json:"id"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Rule      string    `json:"rule"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerModerationWordsGet(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	dbWords, err := cfg.db.GetModerationWords(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve moderation words", err)
+		return
+	}
+
+	words := make([]ModerationWord, 0, len(dbWords))
+	for _, dbWord := range dbWords {
+		words = append(words, ModerationWord{
+			Word:      dbWord.Word,
+			Action:    moderation.Action(dbWord.Action),
+			CreatedAt: dbWord.CreatedAt,
+			UpdatedAt: dbWord.UpdatedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, words)
+}
+
+func (cfg *apiConfig) handlerModerationWordsPut(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Action string `json:"action"`
+	}
+
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	word := moderation.Normalize(r.PathValue("word"))
+	if !moderation.IsWord(word) {
+		respondWithError(w, http.StatusBadRequest, "Word must be a single word without spaces or punctuation", nil)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	action, err := moderation.ParseAction(params.Action)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Action must be one of mask, reject or flag", err)
+		return
+	}
+
+	dbWord, err := cfg.db.UpsertModerationWord(r.Context(), database.UpsertModerationWordParams{
+		Word:   word,
+		Action: database.ModerationAction(action),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save moderation word", err)
+		return
+	}
+
+	err = cfg.reloadModerator(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload moderation rules", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ModerationWord{
+		Word:      dbWord.Word,
+		Action:    moderation.Action(dbWord.Action),
+		CreatedAt: dbWord.CreatedAt,
+		UpdatedAt: dbWord.UpdatedAt,
+	})
+}
+
+func (cfg *apiConfig) handlerModerationWordsDelete(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	deleted, err := cfg.db.DeleteModerationWord(r.Context(), moderation.Normalize(r.PathValue("word")))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete moderation word", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Couldn't find moderation word", nil)
+		return
+	}
+
+	err = cfg.reloadModerator(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload moderation rules", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerModerationReload(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	reloader, ok := cfg.moderator.(moderation.Reloader)
+	if !ok {
+		respondWithError(w, http.StatusNotImplemented, "Moderator can't be reloaded", nil)
+		return
+	}
+	err := reloader.Reload(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload moderation rules", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reloadModerator picks up changes to the moderation words, if the moderator
+// loads its rules at all.
+func (cfg *apiConfig) reloadModerator(ctx context.Context) error {
+	if reloader, ok := cfg.moderator.(moderation.Reloader); ok {
+		return reloader.Reload(ctx)
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerModerationFlagsGet(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Flags      []ModerationFlag `json:"flags"`
+		NextCursor string           `json:"next_cursor,omitempty"`
+	}
+
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	afterCreatedAt, afterID := cursorParams(cursor)
+
+	dbFlags, err := cfg.db.GetUnreviewedModerationFlags(r.Context(), database.GetUnreviewedModerationFlagsParams{
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve moderation flags", err)
+		return
+	}
+
+	resp := response{
+		Flags: []ModerationFlag{},
+	}
+	if len(dbFlags) > limit {
+		dbFlags = dbFlags[:limit]
+		last := dbFlags[len(dbFlags)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	for _, dbFlag := range dbFlags {
+		resp.Flags = append(resp.Flags, ModerationFlag{
+			ID:        dbFlag.ID,
+			ChirpID:   dbFlag.ChirpID,
+			Rule:      dbFlag.Rule,
+			CreatedAt: dbFlag.CreatedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handlerModerationFlagsReview(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	flagIDString := r.PathValue("flagID")
+	flagID, err := uuid.Parse(flagIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid flag ID", err)
+		return
+	}
+
+	_, err = cfg.db.ReviewModerationFlag(r.Context(), flagID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find moderation flag", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't review moderation flag", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
 )
 
 func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, r *http.Request) {
@@ -20,11 +22,12 @@ func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, r *http.Request
 		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
 		return
 	}
-	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	claims, err := cfg.validateJWT(r.Context(), token)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
 		return
 	}
+	userID := claims.UserID
 
 	dbChirp, err := cfg.db.GetChirp(r.Context(), chirpID)
 	if err != nil {
@@ -36,7 +39,7 @@ func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = cfg.db.DeleteChirp(r.Context(), chirpID)
+	err = cfg.deleteChirp(r.Context(), dbChirp)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp", err)
 		return
@@ -44,3 +47,41 @@ func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, r *http.Request
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// deleteChirp removes a chirp along with its plain rechirps and keeps the
+// counters on related chirps in step. Replies and quotes of the chirp are
+// kept and still point at it, see handlerChirpsThread and embedOriginalChirps.
+func (cfg *apiConfig) deleteChirp(ctx context.Context, dbChirp database.Chirp) error {
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.DeleteChirp(ctx, dbChirp.ID)
+	if err != nil {
+		return err
+	}
+
+	if dbChirp.InReplyToID.Valid {
+		err = qtx.DecrementReplyCount(ctx, dbChirp.InReplyToID.UUID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if dbChirp.OriginalChirpID.Valid {
+		switch dbChirp.Kind {
+		case database.ChirpKindRechirp:
+			err = qtx.DecrementRechirpCount(ctx, dbChirp.OriginalChirpID.UUID)
+		case database.ChirpKindQuote:
+			err = qtx.DecrementQuoteCount(ctx, dbChirp.OriginalChirpID.UUID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
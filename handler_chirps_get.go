@@ -2,55 +2,88 @@ package main
 
 import (
 	"net/http"
-	"sort"
 
 	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
 )
 
-func (cfg *apiConfig) handlerChirpsRetrieve(w http.ResponseWriter, r *http.Request) {
-	dbChirps, err := cfg.db.GetChirps(r.Context())
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
-		return
+type chirpsPage struct {
+	Chirps     []Chirp `json:"chirps"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+func (p chirpsPage) chirpRefs() []*Chirp {
+	refs := make([]*Chirp, 0, len(p.Chirps))
+	for i := range p.Chirps {
+		refs = append(refs, &p.Chirps[i])
 	}
+	return refs
+}
 
-	authorID := uuid.Nil
+func (cfg *apiConfig) handlerChirpsRetrieve(w http.ResponseWriter, r *http.Request) {
+	authorID := uuid.NullUUID{}
 	authorIDString := r.URL.Query().Get("author_id")
 	if authorIDString != "" {
-		authorID, err = uuid.Parse(authorIDString)
+		id, err := uuid.Parse(authorIDString)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "Invalid author ID", err)
 			return
 		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
 	}
 
-	sortDirection := "asc"
-	sortDirectionParam := r.URL.Query().Get("sort")
-	if sortDirectionParam == "desc" {
-		sortDirection = "desc"
+	viewerID, err := cfg.viewerID(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
 	}
 
-	chirps := []Chirp{}
-	for _, dbChirp := range dbChirps {
-		if authorID != uuid.Nil && dbChirp.UserID != authorID {
-			continue
-		}
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	afterCreatedAt, afterID := cursorParams(cursor)
 
-		chirps = append(chirps, Chirp{
-			ID:        dbChirp.ID,
-			CreatedAt: dbChirp.CreatedAt,
-			UpdatedAt: dbChirp.UpdatedAt,
-			UserID:    dbChirp.UserID,
-			Body:      dbChirp.Body,
+	// Fetch one extra row so we know whether there is a next page.
+	var dbChirps []database.Chirp
+	if r.URL.Query().Get("sort") == "desc" {
+		dbChirps, err = cfg.db.GetChirpsDesc(r.Context(), database.GetChirpsDescParams{
+			AuthorID:       authorID,
+			AfterCreatedAt: afterCreatedAt,
+			AfterID:        afterID,
+			Limit:          int32(limit + 1),
 		})
+	} else {
+		dbChirps, err = cfg.db.GetChirpsAsc(r.Context(), database.GetChirpsAscParams{
+			AuthorID:       authorID,
+			AfterCreatedAt: afterCreatedAt,
+			AfterID:        afterID,
+			Limit:          int32(limit + 1),
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
+		return
 	}
 
-	sort.Slice(chirps, func(i, j int) bool {
-		if sortDirection == "desc" {
-			return chirps[i].CreatedAt.After(chirps[j].CreatedAt)
-		}
-		return chirps[i].CreatedAt.Before(chirps[j].CreatedAt)
-	})
+	page := chirpsPage{
+		Chirps: []Chirp{},
+	}
+	if len(dbChirps) > limit {
+		dbChirps = dbChirps[:limit]
+		last := dbChirps[len(dbChirps)-1]
+		page.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	for _, dbChirp := range dbChirps {
+		page.Chirps = append(page.Chirps, databaseChirpToChirp(dbChirp))
+	}
+	err = cfg.hydrateChirps(r.Context(), viewerID, page.chirpRefs()...)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
+		return
+	}
 
-	respondWithJSON(w, http.StatusOK, chirps)
+	respondWithJSON(w, http.StatusOK, page)
 }
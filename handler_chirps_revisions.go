@@ -0,0 +1,54 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ChirpRevision struct {
+	ID         uuid.UUID `json:"id"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+func (cfg *apiConfig) handlerChirpsRevisions(w http.ResponseWriter, r *http.Request) {
+	chirpIDString := r.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	_, err = cfg.db.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't get chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	dbRevisions, err := cfg.db.GetChirpRevisions(r.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve revisions", err)
+		return
+	}
+
+	revisions := make([]ChirpRevision, 0, len(dbRevisions))
+	for _, dbRevision := range dbRevisions {
+		revisions = append(revisions, ChirpRevision{
+			ID:         dbRevision.ID,
+			Body:       dbRevision.Body,
+			CreatedAt:  dbRevision.CreatedAt,
+			ReplacedAt: dbRevision.ReplacedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
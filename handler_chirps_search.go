@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/search"
+)
+
+// Postgres marks matches in the headline with private use characters and we
+// swap them for <mark> tags after escaping the snippet, so chirp text can
+// never smuggle its own markup into the response.
+const (
+	snippetStartSel = "\uE000"
+	snippetStopSel  = "\uE001"
+	headlineOptions = "StartSel=" + snippetStartSel + ", StopSel=" + snippetStopSel +
+		", MaxWords=35, MinWords=15, MaxFragments=2"
+)
+
+type chirpSearchResult struct {
+	Chirp
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type chirpSearchPage struct {
+	Results    []chirpSearchResult `json:"results"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerChirpsSearch(w http.ResponseWriter, r *http.Request) {
+	query, err := search.ToTSQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		if errors.Is(err, search.ErrEmptyQuery) {
+			respondWithError(w, http.StatusBadRequest, "Search query is required", err)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Invalid search query", err)
+		return
+	}
+
+	authorID := uuid.NullUUID{}
+	authorIDString := r.URL.Query().Get("author_id")
+	if authorIDString != "" {
+		id, err := uuid.Parse(authorIDString)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid author ID", err)
+			return
+		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	viewerID, err := cfg.viewerID(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	// Results are ranked by relevance unless a date sort is asked for.
+	sortDirection := r.URL.Query().Get("sort")
+	if sortDirection != "asc" && sortDirection != "desc" {
+		sortDirection = "relevance"
+	}
+
+	limit, offset, err := parseOffsetPageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	rows, err := cfg.db.SearchChirps(r.Context(), database.SearchChirpsParams{
+		HeadlineOptions: headlineOptions,
+		Query:           query,
+		AuthorID:        authorID,
+		Sort:            sortDirection,
+		Offset:          int32(offset),
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search chirps", err)
+		return
+	}
+
+	page := chirpSearchPage{
+		Results: []chirpSearchResult{},
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = encodeOffsetCursor(offset + limit)
+	}
+
+	for _, row := range rows {
+		page.Results = append(page.Results, chirpSearchResult{
+			Chirp:   databaseChirpToChirp(row.Chirp),
+			Rank:    row.Rank,
+			Snippet: highlightSnippet(row.Snippet),
+		})
+	}
+	refs := make([]*Chirp, 0, len(page.Results))
+	for i := range page.Results {
+		refs = append(refs, &page.Results[i].Chirp)
+	}
+	err = cfg.hydrateChirps(r.Context(), viewerID, refs...)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+func highlightSnippet(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, snippetStartSel, "<mark>")
+	return strings.ReplaceAll(escaped, snippetStopSel, "</mark>")
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+const (
+	maxThreadAncestors    = 100
+	maxThreadReplyDepth   = 20
+	maxThreadReplyResults = 500
+)
+
+type threadReply struct {
+	Chirp
+	Replies []*threadReply `json:"replies"`
+}
+
+type threadResponse struct {
+	// DeletedAncestorID is set when the ancestor chain stops at a chirp that
+	// has been deleted. Ancestors then starts with that chirp's reply.
+	DeletedAncestorID uuid.NullUUID  `json:"deleted_ancestor_id"`
+	Ancestors         []Chirp        `json:"ancestors"`
+	Chirp             Chirp          `json:"chirp"`
+	Replies           []*threadReply `json:"replies"`
+}
+
+func (cfg *apiConfig) handlerChirpsThread(w http.ResponseWriter, r *http.Request) {
+	chirpIDString := r.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	viewerID, err := cfg.viewerID(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	dbChirp, err := cfg.db.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't get chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	dbAncestors, err := cfg.db.GetChirpAncestors(r.Context(), database.GetChirpAncestorsParams{
+		ID:       chirpID,
+		MaxDepth: maxThreadAncestors,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve ancestors", err)
+		return
+	}
+
+	dbDescendants, err := cfg.db.GetChirpDescendants(r.Context(), database.GetChirpDescendantsParams{
+		ID:       chirpID,
+		MaxDepth: maxThreadReplyDepth,
+		Limit:    maxThreadReplyResults,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve replies", err)
+		return
+	}
+
+	resp := threadResponse{
+		Ancestors: []Chirp{},
+		Chirp:     databaseChirpToChirp(dbChirp),
+		Replies:   []*threadReply{},
+	}
+
+	for _, row := range dbAncestors {
+		resp.Ancestors = append(resp.Ancestors, databaseChirpToChirp(row.Chirp))
+	}
+	// The oldest chirp we found still replies to something, and we didn't stop
+	// because of the depth limit, so its parent must have been deleted.
+	oldest := resp.Chirp
+	if len(resp.Ancestors) > 0 {
+		oldest = resp.Ancestors[0]
+	}
+	if oldest.InReplyToID.Valid && len(resp.Ancestors) < maxThreadAncestors {
+		resp.DeletedAncestorID = oldest.InReplyToID
+	}
+
+	refs := []*Chirp{&resp.Chirp}
+	for i := range resp.Ancestors {
+		refs = append(refs, &resp.Ancestors[i])
+	}
+
+	// Descendants come ordered by depth, so a reply's parent is always placed
+	// in the tree before the reply itself.
+	nodes := map[uuid.UUID]*threadReply{}
+	for _, row := range dbDescendants {
+		node := &threadReply{
+			Chirp:   databaseChirpToChirp(row.Chirp),
+			Replies: []*threadReply{},
+		}
+		nodes[node.ID] = node
+		refs = append(refs, &node.Chirp)
+		if node.InReplyToID.UUID == chirpID {
+			resp.Replies = append(resp.Replies, node)
+			continue
+		}
+		parent, ok := nodes[node.InReplyToID.UUID]
+		if !ok {
+			continue
+		}
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	err = cfg.hydrateChirps(r.Context(), viewerID, refs...)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve thread", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+func (cfg *apiConfig) handlerChirpsUpdate(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	chirpIDString := r.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxChirpRequestSize)
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Request body is too large", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	author, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+
+	moderated, err := cfg.validateChirp(r.Context(), params.Body, author)
+	if err != nil {
+		respondWithChirpValidationError(w, err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// Lock the row so concurrent edits each record the body they replaced.
+	dbChirp, err := qtx.GetChirpForUpdate(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't get chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+	if dbChirp.UserID != userID {
+		respondWithError(w, http.StatusForbidden, "You can't edit this chirp", nil)
+		return
+	}
+	if dbChirp.Kind == database.ChirpKindRechirp {
+		respondWithError(w, http.StatusBadRequest, "Rechirps can't be edited", nil)
+		return
+	}
+	if time.Since(dbChirp.CreatedAt) > cfg.chirpEditWindow {
+		respondWithError(w, http.StatusForbidden, "Chirps can only be edited for "+cfg.chirpEditWindow.String()+" after posting", nil)
+		return
+	}
+
+	if moderated.Body == dbChirp.Body {
+		respondWithError(w, http.StatusBadRequest, "Chirp is unchanged", nil)
+		return
+	}
+
+	_, err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+		ChirpID:   dbChirp.ID,
+		Body:      dbChirp.Body,
+		CreatedAt: dbChirp.UpdatedAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save chirp revision", err)
+		return
+	}
+
+	dbChirp, err = qtx.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+		ID:   dbChirp.ID,
+		Body: moderated.Body,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	err = recordModerationFlags(r.Context(), qtx, dbChirp.ID, moderated)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't flag chirp for review", err)
+		return
+	}
+
+	err = saveChirpEntities(r.Context(), qtx, dbChirp.ID, moderated)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save mentions and hashtags", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	chirp := databaseChirpToChirp(dbChirp)
+	err = cfg.hydrateChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, &chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirp)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+// handlerEmailVerify confirms an address with the token from a verification
+// email and makes it the user's email.
+func (cfg *apiConfig) handlerEmailVerify(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Token string `json:"token"`
+	}
+	type response struct {
+		User
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't verify email", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	verificationToken, err := qtx.GetEmailVerificationTokenForUpdate(r.Context(), auth.HashRefreshToken(params.Token))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "Invalid verification token", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get verification token", err)
+		return
+	}
+	if verificationToken.UsedAt.Valid || time.Now().UTC().After(verificationToken.ExpiresAt) {
+		respondWithError(w, http.StatusBadRequest, "Verification token has expired", nil)
+		return
+	}
+
+	// Someone else may have taken the address since the link was sent.
+	owner, err := qtx.GetUserByEmail(r.Context(), verificationToken.Email)
+	if err == nil && owner.ID != verificationToken.UserID {
+		respondWithError(w, http.StatusConflict, "Email is already in use", nil)
+		return
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+
+	user, err := qtx.VerifyUserEmail(r.Context(), database.VerifyUserEmailParams{
+		ID:    verificationToken.UserID,
+		Email: verificationToken.Email,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't verify email", err)
+		return
+	}
+
+	// Links sent earlier, e.g. for an address the user changed their mind
+	// about, stop working.
+	err = qtx.UseEmailVerificationTokens(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't use verification token", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't verify email", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		User: databaseUserToUser(user),
+	})
+}
+
+// handlerEmailVerificationResend sends another verification email for the
+// address the user is changing to, or for their current one if they haven't
+// verified it yet.
+func (cfg *apiConfig) handlerEmailVerificationResend(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+
+	address, err := cfg.db.GetPendingEmail(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		if user.EmailVerifiedAt.Valid {
+			respondWithError(w, http.StatusConflict, "Email is already verified", nil)
+			return
+		}
+		address = user.Email
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get pending email", err)
+		return
+	}
+
+	err = cfg.sendEmailVerification(r.Context(), userID, address)
+	if errors.Is(err, errTooManyVerificationEmails) {
+		respondWithError(w, http.StatusTooManyRequests, "Too many verification emails, try again later", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send verification email", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+type Follow struct {
+	UserID     uuid.UUID `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type followsPage struct {
+	Count      int64    `json:"count"`
+	Users      []Follow `json:"users"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerFollowersGet(w http.ResponseWriter, r *http.Request) {
+	userID, limit, cursor, ok := cfg.parseFollowsRequest(w, r)
+	if !ok {
+		return
+	}
+	afterCreatedAt, afterID := cursorParams(cursor)
+
+	count, err := cfg.db.CountFollowers(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't count followers", err)
+		return
+	}
+
+	dbFollowers, err := cfg.db.GetFollowers(r.Context(), database.GetFollowersParams{
+		UserID:         userID,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve followers", err)
+		return
+	}
+
+	follows := make([]Follow, 0, len(dbFollowers))
+	for _, dbFollower := range dbFollowers {
+		follows = append(follows, Follow{
+			UserID:     dbFollower.UserID,
+			FollowedAt: dbFollower.CreatedAt,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, newFollowsPage(count, follows, limit))
+}
+
+func (cfg *apiConfig) handlerFollowingGet(w http.ResponseWriter, r *http.Request) {
+	userID, limit, cursor, ok := cfg.parseFollowsRequest(w, r)
+	if !ok {
+		return
+	}
+	afterCreatedAt, afterID := cursorParams(cursor)
+
+	count, err := cfg.db.CountFollowing(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't count followed users", err)
+		return
+	}
+
+	dbFollowing, err := cfg.db.GetFollowing(r.Context(), database.GetFollowingParams{
+		UserID:         userID,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve followed users", err)
+		return
+	}
+
+	follows := make([]Follow, 0, len(dbFollowing))
+	for _, dbFollowee := range dbFollowing {
+		follows = append(follows, Follow{
+			UserID:     dbFollowee.UserID,
+			FollowedAt: dbFollowee.CreatedAt,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, newFollowsPage(count, follows, limit))
+}
+
+// parseFollowsRequest validates the path and paging parameters shared by the
+// follower and following lists. It writes the error response itself and
+// reports whether the handler should continue.
+func (cfg *apiConfig) parseFollowsRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, int, *pageCursor, bool) {
+	userIDString := r.PathValue("userID")
+	userID, err := uuid.Parse(userIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return uuid.Nil, 0, nil, false
+	}
+
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return uuid.Nil, 0, nil, false
+	}
+
+	_, err = cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+			return uuid.Nil, 0, nil, false
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return uuid.Nil, 0, nil, false
+	}
+
+	return userID, limit, cursor, true
+}
+
+// newFollowsPage trims the extra row fetched to detect a next page.
+func newFollowsPage(count int64, follows []Follow, limit int) followsPage {
+	page := followsPage{
+		Count: count,
+		Users: follows,
+	}
+	if len(follows) > limit {
+		page.Users = follows[:limit]
+		last := page.Users[len(page.Users)-1]
+		page.NextCursor = encodeCursor(pageCursor{CreatedAt: last.FollowedAt, ID: last.UserID})
+	}
+	return page
+}
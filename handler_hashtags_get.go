@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gooneraki/chirpy-go/internal/chirptext"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+func (cfg *apiConfig) handlerHashtagChirpsGet(w http.ResponseWriter, r *http.Request) {
+	tag, err := chirptext.ParseHashtag(r.PathValue("tag"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid hashtag", err)
+		return
+	}
+
+	viewerID, err := cfg.viewerID(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	afterCreatedAt, afterID := cursorParams(cursor)
+
+	dbChirps, err := cfg.db.GetChirpsWithHashtag(r.Context(), database.GetChirpsWithHashtagParams{
+		Tag:            tag,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
+		return
+	}
+
+	page := chirpsPage{
+		Chirps: []Chirp{},
+	}
+	if len(dbChirps) > limit {
+		dbChirps = dbChirps[:limit]
+		last := dbChirps[len(dbChirps)-1]
+		page.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	for _, dbChirp := range dbChirps {
+		page.Chirps = append(page.Chirps, databaseChirpToChirp(dbChirp))
+	}
+	err = cfg.hydrateChirps(r.Context(), viewerID, page.chirpRefs()...)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
@@ -1,23 +1,30 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gooneraki/chirpy-go/internal/auth"
 	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/mail"
 )
 
+// mfaChallengeDuration is how long a user has to enter their second factor
+// after getting their password right.
+const mfaChallengeDuration = 5 * time.Minute
+
 func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
 		Password string `json:"password"`
 		Email    string `json:"email"`
 	}
-	type response struct {
-		User
-		Token        string `json:"token"`
-		RefreshToken string `json:"refresh_token"`
+	type mfaResponse struct {
+		MFARequired bool   `json:"mfa_required"`
+		MFAToken    string `json:"mfa_token"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -28,48 +35,94 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := cfg.db.GetUserByEmail(r.Context(), params.Email)
+	email := mail.CanonicalAddress(params.Email)
+	attempt, ok := cfg.startLoginAttempt(w, r, email)
+	if !ok {
+		return
+	}
+
+	user, err := cfg.db.GetUserByEmail(r.Context(), email)
 	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+			return
+		}
+		// Guesses at emails nobody has count too, so they look the same as
+		// wrong passwords.
+		err = cfg.failLoginAttempt(r.Context(), attempt, uuid.NullUUID{})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't record failed login", err)
+			return
+		}
+		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", nil)
+		return
+	}
+
+	match, err := auth.CheckPasswordHash(params.Password, user.HashedPassword)
+	if err != nil || !match {
+		failErr := cfg.failLoginAttempt(r.Context(), attempt, uuid.NullUUID{UUID: user.ID, Valid: true})
+		if failErr != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't record failed login", failErr)
+			return
+		}
 		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
 		return
 	}
 
-	_, err = auth.CheckPasswordHash(params.Password, user.HashedPassword)
+	err = cfg.succeedLoginAttempt(r.Context(), attempt)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record login attempt", err)
+		return
+	}
+
+	cfg.upgradePasswordHash(r.Context(), user, params.Password)
+
+	if user.TotpEnabledAt.Valid {
+		mfaToken, err := cfg.keyring.MakeMFAChallenge(user.ID, mfaChallengeDuration)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't create MFA token", err)
+			return
+		}
+		respondWithJSON(w, http.StatusOK, mfaResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		})
 		return
 	}
 
-	accessToken, err := auth.MakeJWT(
-		user.ID,
-		cfg.jwtSecret,
-		time.Hour,
-	)
+	cfg.logIn(w, r, user)
+}
+
+// logIn starts a new session for a user who has proven who they are and
+// responds with its tokens.
+func (cfg *apiConfig) logIn(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		User
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	err := cfg.resetLoginFailures(r.Context(), user.Email)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't create access JWT", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset failed logins", err)
 		return
 	}
 
-	refreshToken := auth.MakeRefreshToken()
+	session := newRefreshSession(r, user.ID)
+	accessToken, accessTokenID, err := cfg.makeAccessToken(user, session.FamilyID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create access JWT", err)
+		return
+	}
 
-	_, err = cfg.db.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().UTC().Add(time.Hour * 24 * 60),
-	})
+	refreshToken, err := createRefreshToken(r.Context(), cfg.db, session, accessTokenID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't save refresh token", err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, response{
-		User: User{
-			ID:          user.ID,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-			Email:       user.Email,
-			IsChirpyRed: user.IsChirpyRed,
-		},
+		User:         databaseUserToUser(user),
 		Token:        accessToken,
 		RefreshToken: refreshToken,
 	})
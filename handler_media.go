@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/media"
+)
+
+// maxMediaUploadSize is the largest file POST /api/media accepts.
+const maxMediaUploadSize = 5 << 20
+
+func (cfg *apiConfig) handlerMediaUpload(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	// Leave some room for the multipart headers around the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, maxMediaUploadSize+64<<10)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "File is too large", err)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Couldn't read file", err)
+		return
+	}
+	defer file.Close()
+	if header.Size > maxMediaUploadSize {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "File is too large", nil)
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't read file", err)
+		return
+	}
+
+	info, err := media.Inspect(data)
+	if err != nil {
+		if errors.Is(err, media.ErrUnsupportedType) {
+			respondWithError(w, http.StatusUnsupportedMediaType, "Only PNG, JPEG and GIF images are supported", err)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Couldn't read image", err)
+		return
+	}
+
+	thumbnail, thumbnailType, err := media.Thumbnail(data, info)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't make thumbnail", err)
+		return
+	}
+
+	storageKey := uuid.NewString()
+	thumbnailKey := storageKey + "-thumbnail"
+	err = cfg.blobStore.Put(r.Context(), storageKey, bytes.NewReader(data))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store file", err)
+		return
+	}
+	err = cfg.blobStore.Put(r.Context(), thumbnailKey, bytes.NewReader(thumbnail))
+	if err != nil {
+		cfg.blobStore.Delete(context.WithoutCancel(r.Context()), storageKey)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store thumbnail", err)
+		return
+	}
+
+	dbMedia, err := cfg.db.CreateMedia(r.Context(), database.CreateMediaParams{
+		UserID:               userID,
+		ContentType:          info.ContentType,
+		SizeBytes:            int32(len(data)),
+		Width:                int32(info.Width),
+		Height:               int32(info.Height),
+		StorageKey:           storageKey,
+		ThumbnailKey:         thumbnailKey,
+		ThumbnailContentType: thumbnailType,
+	})
+	if err != nil {
+		ctx := context.WithoutCancel(r.Context())
+		cfg.blobStore.Delete(ctx, storageKey)
+		cfg.blobStore.Delete(ctx, thumbnailKey)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save media", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseMediaToMedia(dbMedia))
+}
+
+func (cfg *apiConfig) handlerMediaGet(w http.ResponseWriter, r *http.Request) {
+	cfg.serveMedia(w, r, func(dbMedia database.Medium) (string, string) {
+		return dbMedia.StorageKey, dbMedia.ContentType
+	})
+}
+
+func (cfg *apiConfig) handlerMediaThumbnailGet(w http.ResponseWriter, r *http.Request) {
+	cfg.serveMedia(w, r, func(dbMedia database.Medium) (string, string) {
+		return dbMedia.ThumbnailKey, dbMedia.ThumbnailContentType
+	})
+}
+
+// serveMedia streams one of the blobs of the media in the mediaID path
+// value. Blobs never change once uploaded, so clients may cache them forever.
+func (cfg *apiConfig) serveMedia(w http.ResponseWriter, r *http.Request, blob func(database.Medium) (key, contentType string)) {
+	mediaID, err := uuid.Parse(r.PathValue("mediaID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid media ID", err)
+		return
+	}
+
+	dbMedia, err := cfg.db.GetMedia(r.Context(), mediaID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find media", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get media", err)
+		return
+	}
+
+	key, contentType := blob(dbMedia)
+	f, err := cfg.blobStore.Open(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, media.ErrNotFound) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find media", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't open media", err)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, f)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+func (cfg *apiConfig) handlerUserMentionsGet(w http.ResponseWriter, r *http.Request) {
+	userIDString := r.PathValue("userID")
+	userID, err := uuid.Parse(userIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	viewerID, err := cfg.viewerID(r)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	afterCreatedAt, afterID := cursorParams(cursor)
+
+	_, err = cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+
+	dbChirps, err := cfg.db.GetChirpsMentioningUser(r.Context(), database.GetChirpsMentioningUserParams{
+		UserID:         userID,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve mentions", err)
+		return
+	}
+
+	page := chirpsPage{
+		Chirps: []Chirp{},
+	}
+	if len(dbChirps) > limit {
+		dbChirps = dbChirps[:limit]
+		last := dbChirps[len(dbChirps)-1]
+		page.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	for _, dbChirp := range dbChirps {
+		page.Chirps = append(page.Chirps, databaseChirpToChirp(dbChirp))
+	}
+	err = cfg.hydrateChirps(r.Context(), viewerID, page.chirpRefs()...)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve mentions", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
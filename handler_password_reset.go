@@ -0,0 +1,175 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/mail"
+)
+
+const (
+	// passwordResetTokenDuration is how long a reset link works.
+	passwordResetTokenDuration = time.Hour
+	// maxPasswordResetRequests limits how many reset emails a user can be
+	// sent in passwordResetRequestWindow, so the endpoint can't be used to
+	// flood someone's inbox.
+	maxPasswordResetRequests   = 3
+	passwordResetRequestWindow = 15 * time.Minute
+)
+
+// handlerPasswordForgot emails a password reset link. It responds the same
+// whether or not the email belongs to an account, so it can't be used to
+// find out who has one.
+func (cfg *apiConfig) handlerPasswordForgot(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Email string `json:"email"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	user, err := cfg.db.GetUserByEmail(r.Context(), mail.CanonicalAddress(params.Email))
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+
+	recentRequests, err := cfg.db.CountRecentPasswordResetTokens(r.Context(), database.CountRecentPasswordResetTokensParams{
+		UserID: user.ID,
+		Since:  time.Now().UTC().Add(-passwordResetRequestWindow),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create reset token", err)
+		return
+	}
+	if recentRequests >= maxPasswordResetRequests {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// Reset tokens are random like refresh tokens, and only their hash is
+	// stored for the same reason.
+	resetToken := auth.MakeRefreshToken()
+	err = cfg.db.CreatePasswordResetToken(r.Context(), database.CreatePasswordResetTokenParams{
+		TokenHash: auth.HashRefreshToken(resetToken),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(passwordResetTokenDuration),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create reset token", err)
+		return
+	}
+
+	cfg.sendMail(r.Context(), mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your Chirpy account. If it was you, choose a new password here:\n\n%s\n\nThe link works once and expires in %s. If it wasn't you, you can ignore this email.\n",
+			cfg.appLink("/reset-password", resetToken),
+			passwordResetTokenDuration,
+		),
+	})
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handlerPasswordReset sets a new password with a token from a reset email
+// and logs the user out everywhere.
+func (cfg *apiConfig) handlerPasswordReset(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	resetToken, err := qtx.GetPasswordResetTokenForUpdate(r.Context(), auth.HashRefreshToken(params.Token))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "Invalid reset token", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get reset token", err)
+		return
+	}
+	if resetToken.UsedAt.Valid || time.Now().UTC().After(resetToken.ExpiresAt) {
+		respondWithError(w, http.StatusBadRequest, "Reset token has expired", nil)
+		return
+	}
+
+	user, err := qtx.GetUserByID(r.Context(), resetToken.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	err = cfg.passwordPolicy.Check(params.Password, user.Email)
+	if err != nil {
+		respondWithPasswordPolicyError(w, err)
+		return
+	}
+
+	hashedPassword, err := cfg.hashPassword(params.Password)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't hash password", err)
+		return
+	}
+	_, err = qtx.UpdateUserPassword(r.Context(), database.UpdateUserPasswordParams{
+		ID:             resetToken.UserID,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update password", err)
+		return
+	}
+
+	// Using one reset link uses up every other one sent before it.
+	err = qtx.UsePasswordResetTokens(r.Context(), resetToken.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't use reset token", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		return
+	}
+
+	// Whoever knew the old password may still be logged in somewhere.
+	err = cfg.revokeUserSessions(r.Context(), resetToken.UserID, uuid.NullUUID{})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+func (cfg *apiConfig) handlerChirpsRechirp(w http.ResponseWriter, r *http.Request) {
+	chirpIDString := r.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	if !user.EmailVerifiedAt.Valid {
+		respondWithError(w, http.StatusForbidden, "Verify your email address before posting", nil)
+		return
+	}
+
+	original, err := cfg.resolveOriginalChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't get chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rechirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	rechirp, err := qtx.CreateRechirp(r.Context(), database.CreateRechirpParams{
+		UserID:          userID,
+		OriginalChirpID: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusConflict, "You already rechirped this chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rechirp", err)
+		return
+	}
+
+	err = qtx.IncrementRechirpCount(r.Context(), original.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update rechirp count", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rechirp", err)
+		return
+	}
+
+	resp := databaseChirpToChirp(rechirp)
+	err = cfg.hydrateChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, &resp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get rechirped chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+func (cfg *apiConfig) handlerChirpsUnrechirp(w http.ResponseWriter, r *http.Request) {
+	chirpIDString := r.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	rechirp, err := cfg.db.GetRechirp(r.Context(), database.GetRechirpParams{
+		UserID:          userID,
+		OriginalChirpID: uuid.NullUUID{UUID: chirpID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "You haven't rechirped this chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get rechirp", err)
+		return
+	}
+
+	err = cfg.deleteChirp(r.Context(), rechirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete rechirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
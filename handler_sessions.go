@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	// Current is set on the session of the access token that asked.
+	Current bool `json:"current"`
+}
+
+func (cfg *apiConfig) handlerSessionsGet(w http.ResponseWriter, r *http.Request) {
+	type response struct {
+		Sessions []Session `json:"sessions"`
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	dbSessions, err := cfg.db.GetActiveSessions(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get sessions", err)
+		return
+	}
+
+	sessions := make([]Session, 0, len(dbSessions))
+	for _, dbSession := range dbSessions {
+		sessions = append(sessions, Session{
+			ID:         dbSession.FamilyID,
+			CreatedAt:  dbSession.SessionStartedAt,
+			LastUsedAt: dbSession.LastUsedAt,
+			UserAgent:  dbSession.UserAgent,
+			IPAddress:  dbSession.IpAddress,
+			Current:    dbSession.FamilyID == claims.SessionID,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		Sessions: sessions,
+	})
+}
+
+func (cfg *apiConfig) handlerSessionsDelete(w http.ResponseWriter, r *http.Request) {
+	sessionIDString := r.PathValue("sessionID")
+	sessionID, err := uuid.Parse(sessionIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	revoked, err := cfg.db.RevokeSession(r.Context(), database.RevokeSessionParams{
+		FamilyID: sessionID,
+		UserID:   userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+		return
+	}
+	if revoked == 0 {
+		respondWithError(w, http.StatusNotFound, "Couldn't find session", nil)
+		return
+	}
+
+	err = cfg.denySessionAccessTokens(r.Context(), sessionID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke access tokens", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerSessionsRevokeAll(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	err = cfg.revokeUserSessions(r.Context(), userID, uuid.NullUUID{})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
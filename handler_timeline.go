@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+func (cfg *apiConfig) handlerTimeline(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+	userID := claims.UserID
+
+	limit, cursor, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	afterCreatedAt, afterID := cursorParams(cursor)
+
+	// The timeline is always newest first.
+	dbChirps, err := cfg.db.GetTimeline(r.Context(), database.GetTimelineParams{
+		UserID:         userID,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve timeline", err)
+		return
+	}
+
+	page := chirpsPage{
+		Chirps: []Chirp{},
+	}
+	if len(dbChirps) > limit {
+		dbChirps = dbChirps[:limit]
+		last := dbChirps[len(dbChirps)-1]
+		page.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	for _, dbChirp := range dbChirps {
+		page.Chirps = append(page.Chirps, databaseChirpToChirp(dbChirp))
+	}
+	err = cfg.hydrateChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, page.chirpRefs()...)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve timeline", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
@@ -1,20 +1,36 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/gooneraki/chirpy-go/internal/auth"
 	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/mail"
 )
 
+// handlerUsersUpdate changes only the fields present in the request.
+// Changing the email or password also takes the current password; profile
+// fields don't.
 func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
-		Password string `json:"password"`
-		Email    string `json:"email"`
+		Email           *string `json:"email"`
+		Password        *string `json:"password"`
+		CurrentPassword string  `json:"current_password"`
+		Handle          *string `json:"handle"`
+		DisplayName     *string `json:"display_name"`
+		Bio             *string `json:"bio"`
+		// AvatarMediaID is the ID of an uploaded image, or "" to remove the
+		// avatar.
+		AvatarMediaID *string `json:"avatar_media_id"`
 	}
 	type response struct {
 		User
+		// PendingEmail is the new address until it is verified.
+		PendingEmail string `json:"pending_email,omitempty"`
 	}
 
 	token, err := auth.GetBearerToken(r.Header)
@@ -22,11 +38,12 @@ func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
 		return
 	}
-	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	claims, err := cfg.validateJWT(r.Context(), token)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
 		return
 	}
+	userID := claims.UserID
 
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
@@ -36,29 +53,155 @@ func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	hashedPassword, err := auth.HashPassword(params.Password)
+	user, err := cfg.db.GetUserByID(r.Context(), userID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't hash password", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
 		return
 	}
 
-	user, err := cfg.db.UpdateUser(r.Context(), database.UpdateUserParams{
-		ID:             userID,
-		Email:          params.Email,
-		HashedPassword: hashedPassword,
-	})
+	email := user.Email
+	if params.Email != nil {
+		email, err = mail.NormalizeAddress(*params.Email)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid email address", err)
+			return
+		}
+	}
+	emailChanged := email != user.Email
+
+	if emailChanged || params.Password != nil {
+		// A stolen access token alone isn't enough to take over the account.
+		// Wrong guesses count as failed logins.
+		attempt, ok := cfg.startLoginAttempt(w, r, user.Email)
+		if !ok {
+			return
+		}
+		match, err := auth.CheckPasswordHash(params.CurrentPassword, user.HashedPassword)
+		if err != nil || !match {
+			failErr := cfg.failLoginAttempt(r.Context(), attempt, uuid.NullUUID{UUID: user.ID, Valid: true})
+			if failErr != nil {
+				respondWithError(w, http.StatusInternalServerError, "Couldn't record failed login", failErr)
+				return
+			}
+			respondWithError(w, http.StatusUnauthorized, "Current password is incorrect", err)
+			return
+		}
+		err = cfg.succeedLoginAttempt(r.Context(), attempt)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't record login attempt", err)
+			return
+		}
+	}
+
+	if params.Password != nil {
+		// The password can't contain the current email or the one it is
+		// being changed to.
+		err = cfg.passwordPolicy.Check(*params.Password, user.Email)
+		if err == nil {
+			err = cfg.passwordPolicy.Check(*params.Password, email)
+		}
+		if err != nil {
+			respondWithPasswordPolicyError(w, err)
+			return
+		}
+	}
+
+	profile, err := cfg.checkProfileUpdate(r.Context(), userID, params.Handle, params.DisplayName, params.Bio, params.AvatarMediaID)
+	if err != nil {
+		respondWithProfileValidationError(w, err)
+		return
+	}
+
+	if emailChanged {
+		_, err = cfg.db.GetUserByEmail(r.Context(), email)
+		if err == nil {
+			respondWithError(w, http.StatusConflict, "Email is already in use", nil)
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't check email", err)
+			return
+		}
+	}
+
+	hashedPassword := ""
+	if params.Password != nil {
+		hashedPassword, err = cfg.hashPassword(*params.Password)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't hash password", err)
+			return
+		}
+	}
+
+	// Either every change is saved or none is. Emails are sent and sessions
+	// revoked only once they are.
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	verificationToken := ""
+	if emailChanged {
+		// A new email only replaces the current one once the link sent to
+		// it has been followed.
+		verificationToken, err = createEmailVerificationToken(r.Context(), qtx, userID, email)
+		if errors.Is(err, errTooManyVerificationEmails) {
+			respondWithError(w, http.StatusTooManyRequests, "Too many verification emails, try again later", err)
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't create verification token", err)
+			return
+		}
+	}
+
+	if params.Password != nil {
+		user, err = qtx.UpdateUserPassword(r.Context(), database.UpdateUserPasswordParams{
+			ID:             userID,
+			HashedPassword: hashedPassword,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+			return
+		}
+	}
+
+	// profile is only more than the user ID if the request set a profile field.
+	if profile != (database.UpdateUserProfileParams{ID: userID}) {
+		user, err = qtx.UpdateUserProfile(r.Context(), profile)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update profile", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+		return
+	}
+
+	pendingEmail := ""
+	if emailChanged {
+		cfg.mailEmailVerification(r.Context(), email, verificationToken)
+		pendingEmail = email
+	}
+
+	if params.Password != nil {
+		// A new password logs out every other session, along with their
+		// access tokens.
+		err = cfg.revokeUserSessions(r.Context(), userID, uuid.NullUUID{UUID: claims.SessionID, Valid: true})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+			return
+		}
+	}
 
 	respondWithJSON(w, http.StatusOK, response{
-		User: User{
-			ID:          user.ID,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-			Email:       user.Email,
-			IsChirpyRed: user.IsChirpyRed,
-		},
+		User:         databaseUserToUser(user),
+		PendingEmail: pendingEmail,
 	})
 }
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
 )
 
+// handlerRefresh trades a refresh token for a new access token and a new
+// refresh token. The old refresh token is revoked. Presenting a token that
+// has already been traded means it has leaked, so that revokes every token
+// in its family.
 func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
 	type response struct {
-		Token string `json:"token"`
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
 	}
 
 	refreshToken, err := auth.GetBearerToken(r.Header)
@@ -18,23 +26,85 @@ func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := cfg.db.GetUserFromRefreshToken(r.Context(), refreshToken)
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't refresh token", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
 
-	accessToken, err := auth.MakeJWT(
-		user.ID,
-		cfg.jwtSecret,
-		time.Hour,
-	)
+	// Locking the row makes concurrent refreshes with the same token take
+	// turns, so only one of them can rotate it.
+	dbToken, err := qtx.GetRefreshTokenForUpdate(r.Context(), auth.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get refresh token", err)
+		return
+	}
+
+	if dbToken.ReplacedBy.Valid {
+		err = qtx.RevokeRefreshTokenFamily(r.Context(), dbToken.FamilyID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+			return
+		}
+		err = tx.Commit()
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+			return
+		}
+		err = cfg.denySessionAccessTokens(r.Context(), dbToken.FamilyID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke access tokens", err)
+			return
+		}
+		respondWithError(w, http.StatusUnauthorized, "Refresh token has already been used", nil)
+		return
+	}
+	if dbToken.RevokedAt.Valid || !dbToken.ExpiresAt.After(time.Now().UTC()) {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", nil)
+		return
+	}
+
+	// Tokens carry the user's tier and roles, so read them again in case they
+	// changed since the last refresh.
+	user, err := qtx.GetUserByID(r.Context(), dbToken.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	accessToken, accessTokenID, err := cfg.makeAccessToken(user, dbToken.FamilyID)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
 		return
 	}
 
+	newRefreshToken, err := createRefreshToken(r.Context(), qtx, refreshSessionOf(dbToken), accessTokenID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save refresh token", err)
+		return
+	}
+	err = qtx.RotateRefreshToken(r.Context(), database.RotateRefreshTokenParams{
+		TokenHash:  dbToken.TokenHash,
+		ReplacedBy: auth.HashRefreshToken(newRefreshToken),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke refresh token", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't refresh token", err)
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, response{
-		Token: accessToken,
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
 	})
 }
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/gooneraki/chirpy-go/internal/auth"
 )
 
+// handlerRevoke logs out the session of a refresh token, including the access
+// tokens issued in it.
 func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 	refreshToken, err := auth.GetBearerToken(r.Header)
 	if err != nil {
@@ -13,11 +17,28 @@ func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = cfg.db.RevokeRefreshToken(r.Context(), refreshToken)
+	dbToken, err := cfg.db.GetRefreshToken(r.Context(), auth.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// There is nothing to log out.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get refresh token", err)
+		return
+	}
+
+	err = cfg.db.RevokeRefreshTokenFamily(r.Context(), dbToken.FamilyID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
 		return
 	}
 
+	err = cfg.denySessionAccessTokens(r.Context(), dbToken.FamilyID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke access tokens", err)
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
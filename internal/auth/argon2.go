@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/alexedwards/argon2id"
+)
+
+// PasswordHashParams are the Argon2id cost parameters passwords are hashed
+// with. Salt and key lengths are fixed.
+type PasswordHashParams struct {
+	// Memory is in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// DefaultPasswordHashParams is the second recommended option of RFC 9106.
+// Unlike argon2id.DefaultParams it doesn't depend on the number of CPUs, so
+// every server agrees on whether a hash is up to date.
+var DefaultPasswordHashParams = PasswordHashParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+}
+
+const (
+	passwordSaltLength = 16
+	passwordKeyLength  = 32
+)
+
+func (p PasswordHashParams) argon2id() *argon2id.Params {
+	return &argon2id.Params{
+		Memory:      p.Memory,
+		Iterations:  p.Iterations,
+		Parallelism: p.Parallelism,
+		SaltLength:  passwordSaltLength,
+		KeyLength:   passwordKeyLength,
+	}
+}
+
+// Validate -
+func (p PasswordHashParams) Validate() error {
+	if p.Iterations < 1 {
+		return errors.New("argon2 iterations must be at least 1")
+	}
+	if p.Parallelism < 1 {
+		return errors.New("argon2 parallelism must be at least 1")
+	}
+	// Argon2 needs at least 8 KiB per lane.
+	if p.Memory < 8*uint32(p.Parallelism) {
+		return errors.New("argon2 memory must be at least 8 KiB per lane")
+	}
+	return nil
+}
+
+// HashPasswordWithParams hashes password with Argon2id using params.
+func HashPasswordWithParams(password string, params PasswordHashParams) (string, error) {
+	return argon2id.CreateHash(password, params.argon2id())
+}
+
+// PasswordNeedsRehash reports whether hash was made with parameters other
+// than params, e.g. before they were raised. Since the password is needed to
+// hash it again, this is checked after a successful login.
+func PasswordNeedsRehash(hash string, params PasswordHashParams) (bool, error) {
+	hashParams, salt, key, err := argon2id.DecodeHash(hash)
+	if err != nil {
+		return false, err
+	}
+	return hashParams.Memory != params.Memory ||
+		hashParams.Iterations != params.Iterations ||
+		hashParams.Parallelism != params.Parallelism ||
+		len(salt) != passwordSaltLength ||
+		len(key) != passwordKeyLength, nil
+}
+
+// minTuneMemory is the least memory TunePasswordHashParams will go down to,
+// the OWASP minimum for Argon2id.
+const minTuneMemory = 19 * 1024
+
+// TunePasswordHashParams picks parameters that take about target to hash a
+// password on this machine. It keeps parallelism and starts from maxMemory,
+// halving the memory only while a single iteration is slower than target,
+// then adds iterations until hashing takes at least target. It returns the
+// parameters and how long they took.
+func TunePasswordHashParams(target time.Duration, maxMemory uint32, parallelism uint8) (PasswordHashParams, time.Duration, error) {
+	params := PasswordHashParams{
+		Memory:      maxMemory,
+		Iterations:  1,
+		Parallelism: parallelism,
+	}
+	err := params.Validate()
+	if err != nil {
+		return PasswordHashParams{}, 0, err
+	}
+
+	elapsed, err := timePasswordHash(params)
+	if err != nil {
+		return PasswordHashParams{}, 0, err
+	}
+	for elapsed > target && params.Memory/2 >= minTuneMemory {
+		params.Memory /= 2
+		elapsed, err = timePasswordHash(params)
+		if err != nil {
+			return PasswordHashParams{}, 0, err
+		}
+	}
+
+	// Time scales about linearly with iterations, so estimate the count
+	// from one iteration and correct from there.
+	if elapsed < target {
+		params.Iterations = max(1, uint32(target/max(elapsed, time.Microsecond)))
+		elapsed, err = timePasswordHash(params)
+		if err != nil {
+			return PasswordHashParams{}, 0, err
+		}
+		for elapsed < target {
+			params.Iterations++
+			elapsed, err = timePasswordHash(params)
+			if err != nil {
+				return PasswordHashParams{}, 0, err
+			}
+		}
+	}
+	return params, elapsed, nil
+}
+
+func timePasswordHash(params PasswordHashParams) (time.Duration, error) {
+	start := time.Now()
+	_, err := HashPasswordWithParams("benchmark password", params)
+	return time.Since(start), err
+}
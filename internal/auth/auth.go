@@ -2,10 +2,12 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,18 +21,17 @@ type TokenType string
 const (
 	// TokenTypeAccess -
 	TokenTypeAccess TokenType = "chirpy-access"
+	// TokenTypeMFAChallenge is given out instead of an access token when the
+	// password was right but a second factor is still needed.
+	TokenTypeMFAChallenge TokenType = "chirpy-mfa-challenge"
 )
 
 // ErrNoAuthHeaderIncluded -
 var ErrNoAuthHeaderIncluded = errors.New("no auth header included in request")
 
-// HashPassword -
+// HashPassword hashes password with DefaultPasswordHashParams.
 func HashPassword(password string) (string, error) {
-	hash, err := argon2id.CreateHash(password, argon2id.DefaultParams)
-	if err != nil {
-		return "", err
-	}
-	return hash, nil
+	return HashPasswordWithParams(password, DefaultPasswordHashParams)
 }
 
 // CheckPasswordHash -
@@ -42,52 +43,122 @@ func CheckPasswordHash(password, hash string) (bool, error) {
 	return match, nil
 }
 
-// MakeJWT -
+// DefaultAudience is the aud of access tokens unless the keyring is given
+// another one.
+const DefaultAudience = "chirpy"
+
+// Tier -
+type Tier string
+
+const (
+	// TierFree -
+	TierFree Tier = "free"
+	// TierChirpyRed -
+	TierChirpyRed Tier = "chirpy_red"
+)
+
+// Claims are the claims of an access token.
+type Claims struct {
+	// UserID is the token's subject.
+	UserID uuid.UUID `json:"-"`
+	// SessionID is the refresh token family the token was issued for.
+	SessionID uuid.UUID `json:"sid,omitzero"`
+	Tier      Tier      `json:"tier,omitempty"`
+	Roles     []string  `json:"roles,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// HasRole -
+func (c Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// MakeJWT makes an HS256 token for userID signed with tokenSecret.
 func MakeJWT(
 	userID uuid.UUID,
 	tokenSecret string,
 	expiresIn time.Duration,
 ) (string, error) {
-	signingKey := []byte(tokenSecret)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Issuer:    string(TokenTypeAccess),
-		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
-		Subject:   userID.String(),
-	})
-	return token.SignedString(signingKey)
-}
-
-// ValidateJWT -
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
-	claimsStruct := jwt.RegisteredClaims{}
-	token, err := jwt.ParseWithClaims(
-		tokenString,
-		&claimsStruct,
-		func(token *jwt.Token) (interface{}, error) { return []byte(tokenSecret), nil },
-	)
+	return NewHMACKeyring(tokenSecret).MakeJWT(Claims{UserID: userID}, expiresIn)
+}
+
+// ValidateJWT validates an HS256 token signed with tokenSecret.
+func ValidateJWT(tokenString, tokenSecret string) (Claims, error) {
+	return NewHMACKeyring(tokenSecret).ValidateJWT(tokenString)
+}
+
+// MakeJWT signs an access token with claims. The registered claims are
+// filled in here: the token is valid from now until expiresIn from now and
+// gets a new random ID unless claims.ID is already set.
+func (k *Keyring) MakeJWT(claims Claims, expiresIn time.Duration) (string, error) {
+	return k.makeToken(TokenTypeAccess, claims, expiresIn)
+}
+
+// ValidateJWT checks an access token's signature, algorithm, issuer,
+// audience and validity period and returns its claims.
+func (k *Keyring) ValidateJWT(tokenString string) (Claims, error) {
+	return k.validateToken(TokenTypeAccess, tokenString)
+}
+
+// MakeMFAChallenge makes a token that proves userID got their password right
+// and lets them finish logging in with a second factor. It can't be used as
+// an access token.
+func (k *Keyring) MakeMFAChallenge(userID uuid.UUID, expiresIn time.Duration) (string, error) {
+	return k.makeToken(TokenTypeMFAChallenge, Claims{UserID: userID}, expiresIn)
+}
+
+// ValidateMFAChallenge returns the user an MFA challenge token was made for.
+func (k *Keyring) ValidateMFAChallenge(tokenString string) (uuid.UUID, error) {
+	claims, err := k.validateToken(TokenTypeMFAChallenge, tokenString)
 	if err != nil {
 		return uuid.Nil, err
 	}
+	return claims.UserID, nil
+}
 
-	userIDString, err := token.Claims.GetSubject()
-	if err != nil {
-		return uuid.Nil, err
+func (k *Keyring) makeToken(tokenType TokenType, claims Claims, expiresIn time.Duration) (string, error) {
+	now := time.Now().UTC()
+	tokenID := claims.ID
+	if tokenID == "" {
+		tokenID = uuid.NewString()
+	}
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID,
+		Issuer:    string(tokenType),
+		Subject:   claims.UserID.String(),
+		Audience:  jwt.ClaimStrings{k.audience()},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
 	}
+	return k.sign(claims)
+}
 
-	issuer, err := token.Claims.GetIssuer()
+func (k *Keyring) validateToken(tokenType TokenType, tokenString string) (Claims, error) {
+	claims := Claims{}
+	_, err := jwt.ParseWithClaims(
+		tokenString,
+		&claims,
+		k.keyFunc,
+		jwt.WithValidMethods(k.validMethods()),
+		jwt.WithIssuer(string(tokenType)),
+		jwt.WithAudience(k.audience()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 	if err != nil {
-		return uuid.Nil, err
+		return Claims{}, err
 	}
-	if issuer != string(TokenTypeAccess) {
-		return uuid.Nil, errors.New("invalid issuer")
+	if claims.ID == "" {
+		return Claims{}, errors.New("token has no ID")
 	}
 
-	id, err := uuid.Parse(userIDString)
+	claims.UserID, err = uuid.Parse(claims.Subject)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid user ID: %w", err)
+		return Claims{}, fmt.Errorf("invalid user ID: %w", err)
 	}
-	return id, nil
+	return claims, nil
 }
 
 // GetBearerToken -
@@ -112,6 +183,14 @@ func MakeRefreshToken() string {
 	return hex.EncodeToString(token)
 }
 
+// HashRefreshToken returns the hex encoded SHA-256 digest of a refresh
+// token, which is all the database keeps. Refresh tokens are random, so a
+// plain hash is enough; there is nothing to brute force.
+func HashRefreshToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
 // GetAPIKey -
 func GetAPIKey(headers http.Header) (string, error) {
 	authHeader := headers.Get("Authorization")
@@ -1,10 +1,25 @@
 package auth
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+
 	"github.com/google/uuid"
 )
 
@@ -367,15 +382,15 @@ func TestValidateJWT(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotID, err := ValidateJWT(tt.token, tt.secret)
+			gotClaims, err := ValidateJWT(tt.token, tt.secret)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateJWT() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			if !tt.wantErr && gotID != tt.expectedID {
-				t.Errorf("ValidateJWT() = %v, want %v", gotID, tt.expectedID)
+			if !tt.wantErr && gotClaims.UserID != tt.expectedID {
+				t.Errorf("ValidateJWT() = %v, want %v", gotClaims.UserID, tt.expectedID)
 			}
 		})
 	}
@@ -393,14 +408,14 @@ func TestJWTRoundTrip(t *testing.T) {
 	}
 
 	// Validate the JWT
-	retrievedUserID, err := ValidateJWT(tokenString, secret)
+	retrievedClaims, err := ValidateJWT(tokenString, secret)
 	if err != nil {
 		t.Fatalf("ValidateJWT() failed: %v", err)
 	}
 
 	// Should get back the same user ID
-	if retrievedUserID != userID {
-		t.Errorf("Round trip failed: got %v, want %v", retrievedUserID, userID)
+	if retrievedClaims.UserID != userID {
+		t.Errorf("Round trip failed: got %v, want %v", retrievedClaims.UserID, userID)
 	}
 }
 
@@ -476,21 +491,21 @@ func TestJWTWithDifferentUserIDs(t *testing.T) {
 	}
 
 	// Validate first token returns first user ID
-	retrievedID1, err := ValidateJWT(token1, secret)
+	retrievedClaims1, err := ValidateJWT(token1, secret)
 	if err != nil {
 		t.Fatalf("ValidateJWT() failed for token 1: %v", err)
 	}
-	if retrievedID1 != userID1 {
-		t.Errorf("ValidateJWT() returned %v for token 1, want %v", retrievedID1, userID1)
+	if retrievedClaims1.UserID != userID1 {
+		t.Errorf("ValidateJWT() returned %v for token 1, want %v", retrievedClaims1.UserID, userID1)
 	}
 
 	// Validate second token returns second user ID
-	retrievedID2, err := ValidateJWT(token2, secret)
+	retrievedClaims2, err := ValidateJWT(token2, secret)
 	if err != nil {
 		t.Fatalf("ValidateJWT() failed for token 2: %v", err)
 	}
-	if retrievedID2 != userID2 {
-		t.Errorf("ValidateJWT() returned %v for token 2, want %v", retrievedID2, userID2)
+	if retrievedClaims2.UserID != userID2 {
+		t.Errorf("ValidateJWT() returned %v for token 2, want %v", retrievedClaims2.UserID, userID2)
 	}
 
 	// Tokens should be different
@@ -498,3 +513,841 @@ func TestJWTWithDifferentUserIDs(t *testing.T) {
 		t.Error("Different user IDs generated identical tokens")
 	}
 }
+
+func TestHashRefreshToken(t *testing.T) {
+	token := MakeRefreshToken()
+
+	hash := HashRefreshToken(token)
+	if len(hash) != 64 {
+		t.Errorf("HashRefreshToken() returned %d characters, want 64", len(hash))
+	}
+	if hash == token {
+		t.Error("HashRefreshToken() returned the token itself")
+	}
+	if HashRefreshToken(token) != hash {
+		t.Error("HashRefreshToken() is not deterministic")
+	}
+	if HashRefreshToken(MakeRefreshToken()) == hash {
+		t.Error("Different tokens have the same hash")
+	}
+
+	// echo -n abc | sha256sum
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := HashRefreshToken("abc"); got != want {
+		t.Errorf("HashRefreshToken(%q) = %s, want %s", "abc", got, want)
+	}
+}
+
+func newTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	return private
+}
+
+func newTestEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	return private
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	rsaKey, err := NewSigningKey(newTestRSAKey(t))
+	if err != nil {
+		t.Fatalf("NewSigningKey() failed for RSA: %v", err)
+	}
+	edKey, err := NewSigningKey(newTestEd25519Key(t))
+	if err != nil {
+		t.Fatalf("NewSigningKey() failed for Ed25519: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     *Key
+		wantAlg string
+	}{
+		{name: "HS256", key: NewHMACKey([]byte("secret")), wantAlg: "HS256"},
+		{name: "RS256", key: rsaKey, wantAlg: "RS256"},
+		{name: "EdDSA", key: edKey, wantAlg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := NewKeyring(tt.key)
+			if err != nil {
+				t.Fatalf("NewKeyring() failed: %v", err)
+			}
+			userID := uuid.New()
+			tokenString, err := keyring.MakeJWT(Claims{UserID: userID}, time.Hour)
+			if err != nil {
+				t.Fatalf("MakeJWT() failed: %v", err)
+			}
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, &jwt.RegisteredClaims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified() failed: %v", err)
+			}
+			if token.Header["alg"] != tt.wantAlg {
+				t.Errorf("alg = %v, want %s", token.Header["alg"], tt.wantAlg)
+			}
+			if token.Header["kid"] != tt.key.ID {
+				t.Errorf("kid = %v, want %s", token.Header["kid"], tt.key.ID)
+			}
+
+			gotClaims, err := keyring.ValidateJWT(tokenString)
+			if err != nil {
+				t.Fatalf("ValidateJWT() failed: %v", err)
+			}
+			if gotClaims.UserID != userID {
+				t.Errorf("ValidateJWT() = %v, want %v", gotClaims.UserID, userID)
+			}
+		})
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	oldPrivate := newTestEd25519Key(t)
+	oldKey, err := NewSigningKey(oldPrivate)
+	if err != nil {
+		t.Fatalf("NewSigningKey() failed: %v", err)
+	}
+	oldKeyring, err := NewKeyring(oldKey)
+	if err != nil {
+		t.Fatalf("NewKeyring() failed: %v", err)
+	}
+	oldToken, err := oldKeyring.MakeJWT(Claims{UserID: uuid.New()}, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() failed: %v", err)
+	}
+
+	newKey, err := NewSigningKey(newTestRSAKey(t))
+	if err != nil {
+		t.Fatalf("NewSigningKey() failed: %v", err)
+	}
+	oldVerificationKey, err := NewVerificationKey(oldPrivate.Public())
+	if err != nil {
+		t.Fatalf("NewVerificationKey() failed: %v", err)
+	}
+	if oldVerificationKey.ID != oldKey.ID {
+		t.Errorf("Verification key ID = %s, want %s", oldVerificationKey.ID, oldKey.ID)
+	}
+
+	rotated, err := NewKeyring(newKey, oldVerificationKey)
+	if err != nil {
+		t.Fatalf("NewKeyring() failed: %v", err)
+	}
+	if _, err := rotated.ValidateJWT(oldToken); err != nil {
+		t.Errorf("ValidateJWT() failed for a token signed with the previous key: %v", err)
+	}
+
+	withoutOld, err := NewKeyring(newKey)
+	if err != nil {
+		t.Fatalf("NewKeyring() failed: %v", err)
+	}
+	if _, err := withoutOld.ValidateJWT(oldToken); err == nil {
+		t.Error("ValidateJWT() should have failed once the previous key is dropped")
+	}
+
+	if _, err := NewKeyring(oldVerificationKey); err == nil {
+		t.Error("NewKeyring() should refuse a signing key that can only verify")
+	}
+}
+
+func TestKeyringRejectsAlgorithmConfusion(t *testing.T) {
+	private := newTestRSAKey(t)
+	key, err := NewSigningKey(private)
+	if err != nil {
+		t.Fatalf("NewSigningKey() failed: %v", err)
+	}
+	keyring, err := NewKeyring(key)
+	if err != nil {
+		t.Fatalf("NewKeyring() failed: %v", err)
+	}
+
+	// An attacker who knows the public key signs an HS256 token with it.
+	publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() failed: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Issuer:    string(TokenTypeAccess),
+		Audience:  jwt.ClaimStrings{DefaultAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		Subject:   uuid.New().String(),
+	})
+	forged.Header["kid"] = key.ID
+	forgedString, err := forged.SignedString(publicPEM)
+	if err != nil {
+		t.Fatalf("SignedString() failed: %v", err)
+	}
+
+	if _, err := keyring.ValidateJWT(forgedString); err == nil {
+		t.Error("ValidateJWT() accepted an HS256 token signed with the RSA public key")
+	}
+}
+
+func TestParseKeyPEM(t *testing.T) {
+	rsaPrivate := newTestRSAKey(t)
+	edPrivate := newTestEd25519Key(t)
+	weakRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	must := func(der []byte, err error) []byte {
+		if err != nil {
+			t.Fatalf("Failed to marshal key: %v", err)
+		}
+		return der
+	}
+	encode := func(blockType string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantAlg  string
+		wantSign bool
+		wantErr  bool
+	}{
+		{
+			name:     "PKCS8 RSA private key",
+			data:     encode("PRIVATE KEY", must(x509.MarshalPKCS8PrivateKey(rsaPrivate))),
+			wantAlg:  "RS256",
+			wantSign: true,
+		},
+		{
+			name:     "PKCS1 RSA private key",
+			data:     encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaPrivate)),
+			wantAlg:  "RS256",
+			wantSign: true,
+		},
+		{
+			name:     "PKCS8 Ed25519 private key",
+			data:     encode("PRIVATE KEY", must(x509.MarshalPKCS8PrivateKey(edPrivate))),
+			wantAlg:  "EdDSA",
+			wantSign: true,
+		},
+		{
+			name:    "Ed25519 public key",
+			data:    encode("PUBLIC KEY", must(x509.MarshalPKIXPublicKey(edPrivate.Public()))),
+			wantAlg: "EdDSA",
+		},
+		{
+			name:    "RSA key that is too small",
+			data:    encode("PRIVATE KEY", must(x509.MarshalPKCS8PrivateKey(weakRSA))),
+			wantErr: true,
+		},
+		{
+			name:    "not PEM",
+			data:    []byte("not a key"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKeyPEM(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if key.Method.Alg() != tt.wantAlg {
+				t.Errorf("Method = %s, want %s", key.Method.Alg(), tt.wantAlg)
+			}
+			if (key.signKey != nil) != tt.wantSign {
+				t.Errorf("Can sign = %v, want %v", key.signKey != nil, tt.wantSign)
+			}
+		})
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	rsaKey, err := NewSigningKey(newTestRSAKey(t))
+	if err != nil {
+		t.Fatalf("NewSigningKey() failed: %v", err)
+	}
+	edPrivate := newTestEd25519Key(t)
+	edKey, err := NewVerificationKey(edPrivate.Public())
+	if err != nil {
+		t.Fatalf("NewVerificationKey() failed: %v", err)
+	}
+	keyring, err := NewKeyring(rsaKey, edKey, NewHMACKey([]byte("secret")))
+	if err != nil {
+		t.Fatalf("NewKeyring() failed: %v", err)
+	}
+
+	jwks := keyring.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS() returned %d keys, want 2 without the HMAC secret", len(jwks.Keys))
+	}
+
+	rsaJWK := jwks.Keys[0]
+	if rsaJWK.KeyID != rsaKey.ID || rsaJWK.KeyType != "RSA" || rsaJWK.Algorithm != "RS256" {
+		t.Errorf("First key = %+v, want the RS256 signing key", rsaJWK)
+	}
+	if rsaJWK.E != "AQAB" {
+		t.Errorf("e = %s, want AQAB", rsaJWK.E)
+	}
+
+	edJWK := jwks.Keys[1]
+	if edJWK.KeyID != edKey.ID || edJWK.KeyType != "OKP" || edJWK.Curve != "Ed25519" {
+		t.Errorf("Second key = %+v, want the Ed25519 key", edJWK)
+	}
+
+	if keys := NewHMACKeyring("secret").JWKS().Keys; len(keys) != 0 {
+		t.Errorf("HMAC keyring JWKS() returned %d keys, want 0", len(keys))
+	}
+}
+
+func TestKeyringClaims(t *testing.T) {
+	keyring := NewHMACKeyring("claims_secret")
+	claims := Claims{
+		UserID:    uuid.New(),
+		SessionID: uuid.New(),
+		Tier:      TierChirpyRed,
+		Roles:     []string{"admin"},
+		Scopes:    []string{"chirps:write"},
+	}
+
+	tokenString, err := keyring.MakeJWT(claims, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() failed: %v", err)
+	}
+	got, err := keyring.ValidateJWT(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateJWT() failed: %v", err)
+	}
+
+	if got.UserID != claims.UserID || got.SessionID != claims.SessionID || got.Tier != claims.Tier {
+		t.Errorf("ValidateJWT() = %+v, want %+v", got, claims)
+	}
+	if !got.HasRole("admin") || got.HasRole("moderator") {
+		t.Errorf("Roles = %v, want [admin]", got.Roles)
+	}
+	if len(got.Scopes) != 1 || got.Scopes[0] != "chirps:write" {
+		t.Errorf("Scopes = %v, want [chirps:write]", got.Scopes)
+	}
+	if got.ID == "" {
+		t.Error("Token has no jti")
+	}
+	if got.NotBefore == nil {
+		t.Error("Token has no nbf")
+	}
+	if len(got.Audience) != 1 || got.Audience[0] != DefaultAudience {
+		t.Errorf("Audience = %v, want [%s]", got.Audience, DefaultAudience)
+	}
+
+	other, err := keyring.MakeJWT(claims, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() failed: %v", err)
+	}
+	otherClaims, err := keyring.ValidateJWT(other)
+	if err != nil {
+		t.Fatalf("ValidateJWT() failed: %v", err)
+	}
+	if otherClaims.ID == got.ID {
+		t.Error("Two tokens have the same jti")
+	}
+}
+
+func TestKeyringRejectsOtherTokens(t *testing.T) {
+	secret := "audience_secret"
+	keyring := NewHMACKeyring(secret)
+
+	otherAudience := NewHMACKeyring(secret)
+	otherAudience.Audience = "another-service"
+	otherAudienceToken, err := otherAudience.MakeJWT(Claims{UserID: uuid.New()}, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() failed: %v", err)
+	}
+
+	sign := func(claims jwt.RegisteredClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = hmacKeyID
+		tokenString, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("SignedString() failed: %v", err)
+		}
+		return tokenString
+	}
+	valid := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    string(TokenTypeAccess),
+			Subject:   uuid.NewString(),
+			Audience:  jwt.ClaimStrings{DefaultAudience},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}
+	}
+
+	notYetValid := valid()
+	notYetValid.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	noID := valid()
+	noID.ID = ""
+	noExpiry := valid()
+	noExpiry.ExpiresAt = nil
+	wrongIssuer := valid()
+	wrongIssuer.Issuer = "someone-else"
+
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("SignedString() failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "other audience", token: otherAudienceToken},
+		{name: "not yet valid", token: sign(notYetValid)},
+		{name: "no jti", token: sign(noID)},
+		{name: "no expiry", token: sign(noExpiry)},
+		{name: "wrong issuer", token: sign(wrongIssuer)},
+		{name: "alg none", token: noneToken},
+	}
+
+	if _, err := keyring.ValidateJWT(sign(valid())); err != nil {
+		t.Fatalf("ValidateJWT() failed for a valid token: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keyring.ValidateJWT(tt.token); err == nil {
+				t.Error("ValidateJWT() should have failed")
+			}
+		})
+	}
+}
+
+func TestMemoryDenylist(t *testing.T) {
+	ctx := context.Background()
+	denylist := NewMemoryDenylist()
+
+	err := denylist.Add(ctx, "live", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	err = denylist.Add(ctx, "expired", time.Now().Add(-time.Second))
+	if err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	tests := []struct {
+		tokenID string
+		want    bool
+	}{
+		{tokenID: "live", want: true},
+		{tokenID: "expired", want: false},
+		{tokenID: "unknown", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tokenID, func(t *testing.T) {
+			got, err := denylist.Contains(ctx, tt.tokenID)
+			if err != nil {
+				t.Fatalf("Contains() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Contains(%q) = %v, want %v", tt.tokenID, got, tt.want)
+			}
+		})
+	}
+
+	err = denylist.Prune(ctx)
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if len(denylist.entries) != 1 {
+		t.Errorf("Prune() left %d entries, want 1", len(denylist.entries))
+	}
+	if _, ok := denylist.entries["live"]; !ok {
+		t.Error("Prune() removed an entry that hasn't expired")
+	}
+}
+
+func TestMakeJWTKeepsTokenID(t *testing.T) {
+	keyring := NewHMACKeyring("token_id_secret")
+	claims := Claims{UserID: uuid.New()}
+	claims.ID = "chosen-id"
+
+	tokenString, err := keyring.MakeJWT(claims, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() failed: %v", err)
+	}
+	got, err := keyring.ValidateJWT(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateJWT() failed: %v", err)
+	}
+	if got.ID != "chosen-id" {
+		t.Errorf("jti = %q, want %q", got.ID, "chosen-id")
+	}
+}
+
+func TestTOTPCode(t *testing.T) {
+	// The SHA-1 test vectors from RFC 6238, truncated to six digits.
+	secret := base32NoPadding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("TOTPCode() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TOTPCode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() failed: %v", err)
+	}
+	now := time.Now()
+	step := TOTPStep(now)
+	code := func(step int64) string {
+		code, err := TOTPCode(secret, step)
+		if err != nil {
+			t.Fatalf("TOTPCode() failed: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantOK   bool
+		wantStep int64
+	}{
+		{name: "current code", code: code(step), wantOK: true, wantStep: step},
+		{name: "previous code", code: code(step - 1), wantOK: true, wantStep: step - 1},
+		{name: "next code", code: code(step + 1), wantOK: true, wantStep: step + 1},
+		{name: "old code", code: code(step - 3), wantOK: false},
+		{name: "too short", code: "123", wantOK: false},
+		{name: "empty", code: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok, err := ValidateTOTP(secret, tt.code, now)
+			if err != nil {
+				t.Fatalf("ValidateTOTP() failed: %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("ValidateTOTP() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP() step = %d, want %d", gotStep, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("JBSWY3DPEHPK3PXP", "Chirpy", "user@example.com")
+	want := "otpauth://totp/Chirpy:user@example.com?algorithm=SHA1&digits=6&issuer=Chirpy&period=30&secret=JBSWY3DPEHPK3PXP"
+	if uri != want {
+		t.Errorf("TOTPURI() = %s, want %s", uri, want)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() failed: %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes() returned %d codes, want 10", len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("Recovery code %q isn't formatted like xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("Recovery code %q was generated twice", code)
+		}
+		seen[code] = true
+	}
+
+	hash := HashRecoveryCode(codes[0])
+	typed := strings.ToUpper(strings.ReplaceAll(codes[0], "-", " "))
+	if HashRecoveryCode(typed) != hash {
+		t.Error("HashRecoveryCode() should ignore case, spaces and dashes")
+	}
+	if HashRecoveryCode(codes[1]) == hash {
+		t.Error("Different recovery codes have the same hash")
+	}
+}
+
+func TestMFAChallenge(t *testing.T) {
+	keyring := NewHMACKeyring("mfa_secret")
+	userID := uuid.New()
+
+	challenge, err := keyring.MakeMFAChallenge(userID, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("MakeMFAChallenge() failed: %v", err)
+	}
+	gotID, err := keyring.ValidateMFAChallenge(challenge)
+	if err != nil {
+		t.Fatalf("ValidateMFAChallenge() failed: %v", err)
+	}
+	if gotID != userID {
+		t.Errorf("ValidateMFAChallenge() = %v, want %v", gotID, userID)
+	}
+
+	if _, err := keyring.ValidateJWT(challenge); err == nil {
+		t.Error("ValidateJWT() accepted an MFA challenge as an access token")
+	}
+
+	accessToken, err := keyring.MakeJWT(Claims{UserID: userID}, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() failed: %v", err)
+	}
+	if _, err := keyring.ValidateMFAChallenge(accessToken); err == nil {
+		t.Error("ValidateMFAChallenge() accepted an access token")
+	}
+}
+
+func writeBreachedPasswordFile(t *testing.T, passwords ...string) string {
+	t.Helper()
+	lines := []string{}
+	for i, password := range passwords {
+		sum := sha1.Sum([]byte(password))
+		lines = append(lines, fmt.Sprintf("%X:%d", sum, i+1))
+	}
+	sort.Strings(lines)
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to write breached password file: %v", err)
+	}
+	return path
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	breached, err := OpenBreachedPasswordFile(writeBreachedPasswordFile(t, "correct horse battery staple", "Tr0ub4dor&3"))
+	if err != nil {
+		t.Fatalf("OpenBreachedPasswordFile() error = %v", err)
+	}
+	defer breached.Close()
+
+	policy := PasswordPolicy{
+		MinLength:      8,
+		MinEntropyBits: 40,
+		Breached:       breached,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantRule PasswordRule
+	}{
+		{name: "Strong password", password: "plaid-otter-marmalade"},
+		{name: "Empty", password: "", wantRule: PasswordRuleMinLength},
+		{name: "Too short", password: "aB3$xY", wantRule: PasswordRuleMinLength},
+		{name: "Too long", password: strings.Repeat("ab1!", 65), wantRule: PasswordRuleMaxLength},
+		{name: "Contains email", password: "xx-Jane.Doe@Example.com-xx", wantRule: PasswordRuleContainsEmail},
+		{name: "Contains local part", password: "jane.doe-rocks-2024", wantRule: PasswordRuleContainsEmail},
+		{name: "Repeated characters", password: "aaaaaaaaaaaa", wantRule: PasswordRuleMinEntropy},
+		{name: "Sequence", password: "abcdefghijkl", wantRule: PasswordRuleMinEntropy},
+		{name: "Short lowercase", password: "tulipsss", wantRule: PasswordRuleMinEntropy},
+		{name: "Breached", password: "correct horse battery staple", wantRule: PasswordRuleBreached},
+		{name: "Breached with symbols", password: "Tr0ub4dor&3", wantRule: PasswordRuleBreached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.password, "jane.doe@example.com")
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			var policyErr *PasswordPolicyError
+			if !errors.As(err, &policyErr) {
+				t.Fatalf("Check() error = %v, want a *PasswordPolicyError", err)
+			}
+			if policyErr.Rule != tt.wantRule {
+				t.Errorf("Check() rule = %s, want %s", policyErr.Rule, tt.wantRule)
+			}
+			if policyErr.Message == "" {
+				t.Error("Check() error has no message")
+			}
+		})
+	}
+}
+
+func TestPasswordEntropy(t *testing.T) {
+	tests := []struct {
+		password string
+		want     float64
+	}{
+		{password: "", want: 0},
+		{password: "a", want: math.Log2(26)},
+		{password: "aaaa", want: math.Log2(26) + 3},
+		{password: "abcd", want: 2*math.Log2(26) + 2},
+		{password: "4321", want: 2*math.Log2(10) + 2},
+		{password: "aZ9!", want: 4 * math.Log2(26+26+10+33)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			got := PasswordEntropy(tt.password)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PasswordEntropy(%q) = %f, want %f", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreachedPasswordFile(t *testing.T) {
+	passwords := []string{"password", "123456", "qwerty", "letmein"}
+	f, err := OpenBreachedPasswordFile(writeBreachedPasswordFile(t, passwords...))
+	if err != nil {
+		t.Fatalf("OpenBreachedPasswordFile() error = %v", err)
+	}
+	defer f.Close()
+
+	for _, password := range passwords {
+		got, err := f.Contains(password)
+		if err != nil || !got {
+			t.Errorf("Contains(%q) = %v, %v, want true", password, got, err)
+		}
+	}
+	for _, password := range []string{"Password", "plaid-otter-marmalade", ""} {
+		got, err := f.Contains(password)
+		if err != nil || got {
+			t.Errorf("Contains(%q) = %v, %v, want false", password, got, err)
+		}
+	}
+
+	unsorted := filepath.Join(t.TempDir(), "unsorted.txt")
+	os.WriteFile(unsorted, []byte("FFFFF00000000000000000000000000000000000:1\n0000000000000000000000000000000000000000:1\n"), 0o644)
+	_, err = OpenBreachedPasswordFile(unsorted)
+	if !errors.Is(err, ErrUnsortedBreachedPasswords) {
+		t.Errorf("OpenBreachedPasswordFile() of an unsorted file error = %v, want %v", err, ErrUnsortedBreachedPasswords)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.txt")
+	os.WriteFile(invalid, []byte("password\n"), 0o644)
+	_, err = OpenBreachedPasswordFile(invalid)
+	if err == nil {
+		t.Error("OpenBreachedPasswordFile() of a file without hashes succeeded")
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	cheap := PasswordHashParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}
+	hash, err := HashPasswordWithParams("password123", cheap)
+	if err != nil {
+		t.Fatalf("HashPasswordWithParams() error = %v", err)
+	}
+	match, err := CheckPasswordHash("password123", hash)
+	if err != nil || !match {
+		t.Fatalf("CheckPasswordHash() = %v, %v, want a match", match, err)
+	}
+
+	tests := []struct {
+		name   string
+		params PasswordHashParams
+		want   bool
+	}{
+		{name: "Same parameters", params: cheap, want: false},
+		{name: "More memory", params: PasswordHashParams{Memory: 16 * 1024, Iterations: 1, Parallelism: 1}, want: true},
+		{name: "More iterations", params: PasswordHashParams{Memory: 8 * 1024, Iterations: 2, Parallelism: 1}, want: true},
+		{name: "Other parallelism", params: PasswordHashParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 2}, want: true},
+		{name: "Less memory", params: PasswordHashParams{Memory: 4 * 1024, Iterations: 1, Parallelism: 1}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PasswordNeedsRehash(hash, tt.params)
+			if err != nil {
+				t.Fatalf("PasswordNeedsRehash() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PasswordNeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, err = PasswordNeedsRehash("not a hash", cheap)
+	if err == nil {
+		t.Error("PasswordNeedsRehash() of an invalid hash succeeded")
+	}
+
+	defaultHash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if got, _ := PasswordNeedsRehash(defaultHash, DefaultPasswordHashParams); got {
+		t.Error("HashPassword() made a hash that needs rehashing with the default parameters")
+	}
+}
+
+func TestPasswordHashParamsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  PasswordHashParams
+		wantErr bool
+	}{
+		{name: "Default", params: DefaultPasswordHashParams},
+		{name: "No iterations", params: PasswordHashParams{Memory: 1024, Iterations: 0, Parallelism: 1}, wantErr: true},
+		{name: "No parallelism", params: PasswordHashParams{Memory: 1024, Iterations: 1, Parallelism: 0}, wantErr: true},
+		{name: "Too little memory", params: PasswordHashParams{Memory: 16, Iterations: 1, Parallelism: 4}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTunePasswordHashParams(t *testing.T) {
+	target := 20 * time.Millisecond
+	params, elapsed, err := TunePasswordHashParams(target, 32*1024, 1)
+	if err != nil {
+		t.Fatalf("TunePasswordHashParams() error = %v", err)
+	}
+	if err := params.Validate(); err != nil {
+		t.Errorf("TunePasswordHashParams() = %+v, which isn't valid: %v", params, err)
+	}
+	if params.Parallelism != 1 || params.Memory > 32*1024 {
+		t.Errorf("TunePasswordHashParams() = %+v, want parallelism 1 and at most 32 MiB", params)
+	}
+	if elapsed < target {
+		t.Errorf("TunePasswordHashParams() took %s, want at least %s", elapsed, target)
+	}
+
+	_, _, err = TunePasswordHashParams(target, 32*1024, 0)
+	if err == nil {
+		t.Error("TunePasswordHashParams() with no parallelism succeeded")
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTokenRevoked -
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// Denylist keeps the IDs (jti) of revoked access tokens until the tokens
+// expire. Past that the token is rejected anyway, so Prune can forget it.
+type Denylist interface {
+	Add(ctx context.Context, tokenID string, expiresAt time.Time) error
+	Contains(ctx context.Context, tokenID string) (bool, error)
+	Prune(ctx context.Context) error
+}
+
+// MemoryDenylist is a Denylist for a single server process. Revocations are
+// lost on restart and aren't shared with other instances.
+type MemoryDenylist struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// NewMemoryDenylist -
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{entries: map[string]time.Time{}}
+}
+
+// Add -
+func (d *MemoryDenylist) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if current, ok := d.entries[tokenID]; !ok || expiresAt.After(current) {
+		d.entries[tokenID] = expiresAt
+	}
+	return nil
+}
+
+// Contains -
+func (d *MemoryDenylist) Contains(ctx context.Context, tokenID string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	expiresAt, ok := d.entries[tokenID]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+// Prune -
+func (d *MemoryDenylist) Prune(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	for tokenID, expiresAt := range d.entries {
+		if !now.Before(expiresAt) {
+			delete(d.entries, tokenID)
+		}
+	}
+	return nil
+}
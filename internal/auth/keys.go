@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing or verifying.
+const minRSAKeyBits = 2048
+
+// hmacKeyID is the kid of the shared secret key. There is only ever one.
+const hmacKeyID = "hs256"
+
+// ErrUnknownKey -
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Key is a JWT signing or verification key. Keys parsed from a public key
+// can only verify.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// signKey is nil for keys that can only verify.
+	signKey   any
+	verifyKey any
+	// public is set for asymmetric keys, which can be published in a JWKS.
+	public crypto.PublicKey
+}
+
+// NewHMACKey makes an HS256 key from a shared secret.
+func NewHMACKey(secret []byte) *Key {
+	return &Key{
+		ID:        hmacKeyID,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewSigningKey makes an RS256 key from an RSA private key or an EdDSA key
+// from an Ed25519 private key. Its ID is derived from the public key, see
+// KeyID.
+func NewSigningKey(private crypto.Signer) (*Key, error) {
+	key, err := NewVerificationKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.signKey = private
+	return key, nil
+}
+
+// NewVerificationKey makes a key that can only verify tokens, e.g. one that
+// has been rotated out but whose tokens haven't expired yet.
+func NewVerificationKey(public crypto.PublicKey) (*Key, error) {
+	var method jwt.SigningMethod
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key has %d bits, at least %d are required", public.N.BitLen(), minRSAKeyBits)
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", public)
+	}
+
+	id, err := KeyID(public)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		ID:        id,
+		Method:    method,
+		verifyKey: public,
+		public:    public,
+	}, nil
+}
+
+// ParseKeyPEM reads a PEM encoded RSA or Ed25519 key. Private keys can sign,
+// public keys can only verify.
+func ParseKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", private)
+		}
+		return NewSigningKey(signer)
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewSigningKey(private)
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewVerificationKey(public)
+	case "RSA PUBLIC KEY":
+		public, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewVerificationKey(public)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// KeyID derives a stable kid from a public key, so the same key always gets
+// the same ID without anyone having to pick one.
+func KeyID(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(digest[:16]), nil
+}
+
+// Keyring signs tokens with one key and verifies them with any of its keys,
+// picked by the token's kid header. Keeping the previous key around while
+// tokens signed with it expire makes key rotation seamless.
+type Keyring struct {
+	// Audience is the aud claim tokens are issued for and must carry to be
+	// accepted. It defaults to DefaultAudience.
+	Audience string
+
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewKeyring makes a keyring that signs with signing and also accepts tokens
+// signed by any of verifying.
+func NewKeyring(signing *Key, verifying ...*Key) (*Keyring, error) {
+	if signing.signKey == nil {
+		return nil, fmt.Errorf("key %s can't sign", signing.ID)
+	}
+	k := &Keyring{
+		signing: signing,
+		keys:    map[string]*Key{signing.ID: signing},
+	}
+	for _, key := range verifying {
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key %s", key.ID)
+		}
+		k.keys[key.ID] = key
+	}
+	return k, nil
+}
+
+// NewHMACKeyring makes a keyring that signs and verifies with a shared
+// secret only.
+func NewHMACKeyring(secret string) *Keyring {
+	key := NewHMACKey([]byte(secret))
+	return &Keyring{
+		signing: key,
+		keys:    map[string]*Key{key.ID: key},
+	}
+}
+
+func (k *Keyring) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.Method, claims)
+	token.Header["kid"] = k.signing.ID
+	return token.SignedString(k.signing.signKey)
+}
+
+// keyFunc finds the key a token claims to be signed with. The algorithm in
+// the token must be the one of that key, otherwise e.g. an RSA public key
+// could be used as an HMAC secret.
+func (k *Keyring) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("key %s doesn't use %s", kid, token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
+
+func (k *Keyring) audience() string {
+	if k.Audience == "" {
+		return DefaultAudience
+	}
+	return k.Audience
+}
+
+func (k *Keyring) validMethods() []string {
+	methods := []string{}
+	seen := map[string]bool{}
+	for _, key := range k.keys {
+		alg := key.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// N and E are set for RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve and X are set for Ed25519 keys.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the keyring. The HMAC secret is never
+// included, so a keyring with only a shared secret has an empty set.
+func (k *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	// The signing key goes first; the order of the rest doesn't matter.
+	keys := []*Key{k.signing}
+	for _, key := range k.keys {
+		if key != k.signing {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		jwk := JWK{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Method.Alg(),
+		}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
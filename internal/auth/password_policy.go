@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordRule names a rule of a PasswordPolicy.
+type PasswordRule string
+
+const (
+	PasswordRuleMinLength     PasswordRule = "min_length"
+	PasswordRuleMaxLength     PasswordRule = "max_length"
+	PasswordRuleMinEntropy    PasswordRule = "min_entropy"
+	PasswordRuleContainsEmail PasswordRule = "contains_email"
+	PasswordRuleBreached      PasswordRule = "breached"
+)
+
+// maxPasswordLength keeps hashing cheap; nobody needs a longer password.
+const maxPasswordLength = 256
+
+// PasswordPolicyError says which rule a password broke.
+type PasswordPolicyError struct {
+	Rule    PasswordRule
+	Message string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Message
+}
+
+// PasswordPolicy decides which passwords users may choose. It is checked
+// when a password is set, separately from HashPassword.
+type PasswordPolicy struct {
+	// MinLength is counted in characters.
+	MinLength int
+	// MinEntropyBits is the least PasswordEntropy a password needs.
+	MinEntropyBits float64
+	// Breached, if set, rejects passwords known from data breaches.
+	Breached BreachedPasswords
+}
+
+// Check returns a *PasswordPolicyError for the first rule password breaks,
+// or another error if the breached password list couldn't be read. email is
+// the account's address; passwords can't contain it or its local part.
+func (p PasswordPolicy) Check(password, email string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return &PasswordPolicyError{
+			Rule:    PasswordRuleMinLength,
+			Message: fmt.Sprintf("Password must be at least %d characters long", p.MinLength),
+		}
+	}
+	if length > maxPasswordLength {
+		return &PasswordPolicyError{
+			Rule:    PasswordRuleMaxLength,
+			Message: fmt.Sprintf("Password can't be longer than %d characters", maxPasswordLength),
+		}
+	}
+
+	if containsEmail(password, email) {
+		return &PasswordPolicyError{
+			Rule:    PasswordRuleContainsEmail,
+			Message: "Password can't contain your email address",
+		}
+	}
+
+	if PasswordEntropy(password) < p.MinEntropyBits {
+		return &PasswordPolicyError{
+			Rule:    PasswordRuleMinEntropy,
+			Message: "Password is too easy to guess; make it longer or mix in other kinds of characters",
+		}
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			return &PasswordPolicyError{
+				Rule:    PasswordRuleBreached,
+				Message: "Password has appeared in a data breach; choose another one",
+			}
+		}
+	}
+	return nil
+}
+
+// minEmailPartLength keeps short local parts like "jo" from ruling out
+// every password that happens to contain them.
+const minEmailPartLength = 3
+
+func containsEmail(password, email string) bool {
+	password = strings.ToLower(password)
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+	if strings.Contains(password, email) {
+		return true
+	}
+	local, _, _ := strings.Cut(email, "@")
+	return len(local) >= minEmailPartLength && strings.Contains(password, local)
+}
+
+// PasswordEntropy estimates the strength of a password in bits. Each
+// character is worth log2 of the size of the character classes the password
+// draws from (lowercase, uppercase, digits, symbols, other), except that
+// characters repeating the previous one or continuing a run like "abc" or
+// "321" are worth a single bit.
+func PasswordEntropy(password string) float64 {
+	var hasLower, hasUpper, hasDigit, hasSymbol, hasOther bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			hasSymbol = true
+		default:
+			hasOther = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{
+		{hasLower, 26},
+		{hasUpper, 26},
+		{hasDigit, 10},
+		{hasSymbol, 33},
+		{hasOther, 100},
+	} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	bitsPerChar := math.Log2(float64(pool))
+
+	bits := 0.0
+	var prev, prevStep rune
+	for i, r := range []rune(password) {
+		step := r - prev
+		switch {
+		case i > 0 && step == 0:
+			bits++
+		case i > 1 && (step == 1 || step == -1) && step == prevStep:
+			bits++
+		default:
+			bits += bitsPerChar
+		}
+		prev, prevStep = r, step
+	}
+	return bits
+}
+
+// BreachedPasswords checks passwords against a list of ones known from data
+// breaches.
+type BreachedPasswords interface {
+	Contains(password string) (bool, error)
+}
+
+// breachedPrefixLength is how many hex digits of a SHA-1 digest pick a
+// range, as in the Pwned Passwords range API.
+const breachedPrefixLength = 5
+
+// BreachedPasswordFile is a breached password list in the format of the
+// Pwned Passwords download: one uppercase hex SHA-1 digest per line,
+// optionally followed by ":count", sorted by digest. Like the k-anonymity
+// range API, lookups only read the range of lines sharing the first five
+// hex digits of the digest, found through an index built when the file is
+// opened, so even the full list isn't loaded into memory.
+type BreachedPasswordFile struct {
+	file *os.File
+	// ranges[p] is the offset of the first line whose digest starts with
+	// the prefix p; ranges[p+1] is where that range ends.
+	ranges []int64
+}
+
+// ErrUnsortedBreachedPasswords -
+var ErrUnsortedBreachedPasswords = errors.New("breached password file isn't sorted by hash")
+
+// OpenBreachedPasswordFile indexes the file at path. It keeps the file open
+// until Close.
+func OpenBreachedPasswordFile(path string) (*BreachedPasswordFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	ranges, err := indexBreachedPasswords(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &BreachedPasswordFile{file: file, ranges: ranges}, nil
+}
+
+func indexBreachedPasswords(r io.Reader) ([]int64, error) {
+	ranges := make([]int64, 1<<(4*breachedPrefixLength)+1)
+	next := 0
+	offset := int64(0)
+	lastDigest := ""
+
+	reader := bufio.NewReader(r)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" {
+			break
+		}
+
+		digest, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+		digest = strings.ToUpper(digest)
+		if digest != "" {
+			if len(digest) != 2*sha1.Size {
+				return nil, fmt.Errorf("line %d: not a SHA-1 hash", lineNumber)
+			}
+			if digest < lastDigest {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, ErrUnsortedBreachedPasswords)
+			}
+			lastDigest = digest
+			prefix, err := parseBreachedPrefix(digest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			for ; next <= prefix; next++ {
+				ranges[next] = offset
+			}
+		}
+
+		offset += int64(len(line))
+		if err == io.EOF {
+			break
+		}
+	}
+	for ; next < len(ranges); next++ {
+		ranges[next] = offset
+	}
+	return ranges, nil
+}
+
+func parseBreachedPrefix(digest string) (int, error) {
+	prefix := 0
+	for _, c := range digest[:breachedPrefixLength] {
+		var v int
+		switch {
+		case c >= '0' && c <= '9':
+			v = int(c - '0')
+		case c >= 'A' && c <= 'F':
+			v = int(c-'A') + 10
+		default:
+			return 0, errors.New("not a SHA-1 hash")
+		}
+		prefix = prefix<<4 | v
+	}
+	return prefix, nil
+}
+
+// Contains -
+func (f *BreachedPasswordFile) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, err := parseBreachedPrefix(digest)
+	if err != nil {
+		return false, err
+	}
+
+	start, end := f.ranges[prefix], f.ranges[prefix+1]
+	block := make([]byte, end-start)
+	_, err = f.file.ReadAt(block, start)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	for _, line := range bytes.Split(block, []byte("\n")) {
+		lineDigest, _, _ := strings.Cut(strings.TrimSpace(string(line)), ":")
+		if strings.EqualFold(lineDigest, digest) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Close -
+func (f *BreachedPasswordFile) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPDigits is the length of a TOTP code.
+	TOTPDigits = 6
+	// TOTPPeriod is how long each TOTP code is valid.
+	TOTPPeriod = 30 * time.Second
+	// totpSkew is how many periods before or after the current one are also
+	// accepted, for clocks that are a little off.
+	totpSkew = 1
+	// totpSecretBytes is the size of a TOTP secret, the 160 bits RFC 4226
+	// recommends.
+	totpSecretBytes = 20
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a new random TOTP secret, base32 encoded as
+// authenticator apps expect it.
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretBytes)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return base32NoPadding.EncodeToString(secret), nil
+}
+
+// TOTPURI returns the otpauth:// URI authenticator apps enroll a secret
+// from, usually shown as a QR code.
+func TOTPURI(secret, issuer, account string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(int(TOTPPeriod.Seconds())))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TOTPStep is the number of the TOTP period t falls in.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod.Seconds())
+}
+
+// TOTPCode computes the code for secret in the given step (RFC 6238 with
+// HMAC-SHA1).
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulus := uint32(1)
+	for range TOTPDigits {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulus), nil
+}
+
+// ValidateTOTP checks code against secret at time t, allowing for a period
+// of clock skew either way. It returns the step the code belongs to, so
+// callers can refuse to accept a code for the same step twice.
+func ValidateTOTP(secret, code string, t time.Time) (int64, bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false, nil
+	}
+
+	current := TOTPStep(t)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		want, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(want)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+const (
+	// recoveryCodeChars is the length of a recovery code without its dash:
+	// two groups of five base32 characters, 50 bits of randomness.
+	recoveryCodeChars = 10
+	// recoveryCodeBytes is the fewest random bytes that fill
+	// recoveryCodeChars base32 characters, at five bits each.
+	recoveryCodeBytes = (recoveryCodeChars*5 + 7) / 8
+)
+
+// GenerateRecoveryCodes returns n new one-time recovery codes such as
+// "k3vqm-7xw2a".
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for range n {
+		raw := make([]byte, recoveryCodeBytes)
+		_, err := rand.Read(raw)
+		if err != nil {
+			return nil, err
+		}
+		encoded := strings.ToLower(base32NoPadding.EncodeToString(raw))[:recoveryCodeChars]
+		codes = append(codes, encoded[:recoveryCodeChars/2]+"-"+encoded[recoveryCodeChars/2:])
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hex encoded SHA-256 digest of a recovery
+// code, ignoring case, spaces and dashes so users can type it however they
+// like. Like refresh tokens, recovery codes are random enough that a plain
+// hash is fine.
+func HashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	digest := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,37 @@
+package chirptext
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// URLWeight is how many characters a URL counts as, however long it is.
+const URLWeight = 23
+
+var urlRegex = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+
+// URLs returns the byte ranges of the URLs in a chirp. Punctuation at the end
+// of a URL, as in "see https://example.com.", is not part of it.
+func URLs(body string) [][2]int {
+	ranges := [][2]int{}
+	for _, loc := range urlRegex.FindAllStringIndex(body, -1) {
+		url := strings.TrimRight(body[loc[0]:loc[1]], `.,:;!?'")]}`)
+		ranges = append(ranges, [2]int{loc[0], loc[0] + len(url)})
+	}
+	return ranges
+}
+
+// Length is the length of a chirp as users perceive it: the number of
+// grapheme clusters, so an emoji made of several code points counts once,
+// with every URL counted as URLWeight.
+func Length(body string) int {
+	length := 0
+	last := 0
+	for _, url := range URLs(body) {
+		length += uniseg.GraphemeClusterCount(body[last:url[0]]) + URLWeight
+		last = url[1]
+	}
+	return length + uniseg.GraphemeClusterCount(body[last:])
+}
@@ -0,0 +1,208 @@
+package chirptext
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{
+			name: "ascii",
+			body: "hello world",
+			want: 11,
+		},
+		{
+			name: "empty",
+			body: "",
+			want: 0,
+		},
+		{
+			name: "accented letters",
+			body: "café",
+			want: 4,
+		},
+		{
+			name: "combining marks",
+			body: "cafe\u0301",
+			want: 4,
+		},
+		{
+			name: "emoji",
+			body: strings.Repeat("😀", 50),
+			want: 50,
+		},
+		{
+			name: "family emoji",
+			body: "👨‍👩‍👧‍👦",
+			want: 1,
+		},
+		{
+			name: "flag",
+			body: "🇬🇷",
+			want: 1,
+		},
+		{
+			name: "url",
+			body: "look https://example.com/a/very/long/path?with=query&and=more",
+			want: 5 + URLWeight,
+		},
+		{
+			name: "short url",
+			body: "http://a.io",
+			want: URLWeight,
+		},
+		{
+			name: "url followed by punctuation",
+			body: "see https://example.com.",
+			want: 4 + URLWeight + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Length(tt.body); got != tt.want {
+				t.Errorf("Length() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLs(t *testing.T) {
+	body := "a http://x.com, b (https://y.org/p) ftp://z.net"
+	got := []string{}
+	for _, url := range URLs(body) {
+		got = append(got, body[url[0]:url[1]])
+	}
+	want := []string{"http://x.com", "https://y.org/p"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("URLs() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckHandle(t *testing.T) {
+	tests := []struct {
+		handle  string
+		wantErr error
+	}{
+		{handle: "jane"},
+		{handle: "Jane_Doe_99"},
+		{handle: "abc"},
+		{handle: "a23456789012345"},
+		{handle: "ab", wantErr: ErrInvalidHandle},
+		{handle: "a234567890123456", wantErr: ErrInvalidHandle},
+		{handle: "", wantErr: ErrInvalidHandle},
+		{handle: "@jane", wantErr: ErrInvalidHandle},
+		{handle: "jane.doe", wantErr: ErrInvalidHandle},
+		{handle: "jane doe", wantErr: ErrInvalidHandle},
+		{handle: "jäne", wantErr: ErrInvalidHandle},
+		{handle: "support", wantErr: ErrReservedHandle},
+		{handle: "Settings", wantErr: ErrReservedHandle},
+		{handle: "the_admin", wantErr: ErrReservedHandle},
+		{handle: "ChirpyHelp", wantErr: ErrReservedHandle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.handle, func(t *testing.T) {
+			err := CheckHandle(tt.handle)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckHandle(%q) error = %v, want %v", tt.handle, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntities(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		masked [][2]int
+		want   []Entity
+	}{
+		{
+			name: "mention and hashtag",
+			body: "@jane_doe loves #GoLang!",
+			want: []Entity{
+				{Kind: EntityMention, Text: "jane_doe", Start: 0, End: 9},
+				{Kind: EntityHashtag, Text: "GoLang", Start: 16, End: 23},
+			},
+		},
+		{
+			name: "after punctuation and non-ascii text",
+			body: "(@jane) 日本語 #東京",
+			want: []Entity{
+				{Kind: EntityMention, Text: "jane", Start: 1, End: 6},
+				{Kind: EntityHashtag, Text: "東京", Start: 18, End: 25},
+			},
+		},
+		{
+			name: "inside a url",
+			body: "read https://example.com/@jane/post#top #news",
+			want: []Entity{
+				{Kind: EntityHashtag, Text: "news", Start: 40, End: 45},
+			},
+		},
+		{
+			name:   "touching masked text",
+			body:   "#**** and #nice**** but #fine",
+			masked: [][2]int{{1, 5}, {15, 19}},
+			want: []Entity{
+				{Kind: EntityHashtag, Text: "fine", Start: 24, End: 29},
+			},
+		},
+		{
+			name: "emails and words",
+			body: "mail jane@example.com about C# and @jane@example.com",
+			want: []Entity{},
+		},
+		{
+			name: "invalid handles and hashtags",
+			body: "@jo @waytoolongforahandle @jäne #123 #_",
+			want: []Entity{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Entities(tt.body, tt.masked)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Entities(%q) = %+v, want %+v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHashtag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr error
+	}{
+		{tag: "GoLang", want: "golang"},
+		{tag: "#GoLang", want: "golang"},
+		{tag: "2024_Olympics", want: "2024_olympics"},
+		{tag: "Straße", want: "straße"},
+		{tag: "", wantErr: ErrInvalidHashtag},
+		{tag: "2024", wantErr: ErrInvalidHashtag},
+		{tag: "go-lang", wantErr: ErrInvalidHashtag},
+		{tag: strings.Repeat("a", MaxHashtagLength+1), wantErr: ErrInvalidHashtag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := ParseHashtag(tt.tag)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseHashtag(%q) error = %v, want %v", tt.tag, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHashtag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package chirptext
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxHashtagLength is the longest hashtag, without the #, in characters.
+const MaxHashtagLength = 50
+
+// ErrInvalidHashtag -
+var ErrInvalidHashtag = errors.New("hashtags are letters, digits or underscores with at least one letter")
+
+// EntityKind -
+type EntityKind string
+
+const (
+	EntityMention EntityKind = "mention"
+	EntityHashtag EntityKind = "hashtag"
+)
+
+// Entity is a mention or hashtag in a chirp. Text is what follows the @ or
+// #; Start and End are the byte range of the whole entity, sign included.
+type Entity struct {
+	Kind  EntityKind
+	Text  string
+	Start int
+	End   int
+}
+
+var entityRegex = regexp.MustCompile(`[@#][\p{L}\p{N}_]+`)
+
+// Entities finds the mentions and hashtags in a chirp body, in order.
+// Anything inside a URL, or touching one of the masked ranges, is left out:
+// "https://example.com/#top" has no hashtag, and neither has "#****" or
+// "#nice****" once a word has been masked.
+//
+// Mentions are only returned if what follows the @ is a valid handle, see
+// CheckHandle. They aren't checked against existing users.
+func Entities(body string, masked [][2]int) []Entity {
+	skip := append(URLs(body), masked...)
+	entities := []Entity{}
+	for _, loc := range entityRegex.FindAllStringIndex(body, -1) {
+		if !entityBoundary(body, loc[0], loc[1]) || touchesAny(loc, skip) {
+			continue
+		}
+
+		text := body[loc[0]+1 : loc[1]]
+		entity := Entity{Text: text, Start: loc[0], End: loc[1]}
+		switch body[loc[0]] {
+		case '@':
+			if CheckHandle(text) != nil {
+				continue
+			}
+			entity.Kind = EntityMention
+		case '#':
+			if _, err := ParseHashtag(text); err != nil {
+				continue
+			}
+			entity.Kind = EntityHashtag
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+// ParseHashtag checks a hashtag, with or without the #, and returns it in
+// the lowercase form hashtags are looked up by.
+func ParseHashtag(tag string) (string, error) {
+	tag = strings.TrimPrefix(tag, "#")
+	if tag == "" || utf8.RuneCountInString(tag) > MaxHashtagLength {
+		return "", ErrInvalidHashtag
+	}
+	hasLetter := false
+	for _, r := range tag {
+		if !isEntityRune(r) {
+			return "", ErrInvalidHashtag
+		}
+		hasLetter = hasLetter || unicode.IsLetter(r)
+	}
+	if !hasLetter {
+		return "", ErrInvalidHashtag
+	}
+	return strings.ToLower(tag), nil
+}
+
+func isEntityRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// entityBoundary reports whether body[start:end] stands on its own, so the
+// "@" of an email address or the "#" in "C#" doesn't start an entity, and
+// "@jane@example.com" isn't a mention of jane.
+func entityBoundary(body string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(body[:start]); start > 0 && (isEntityRune(before) || strings.ContainsRune("@#&", before)) {
+		return false
+	}
+	after, _ := utf8.DecodeRuneInString(body[end:])
+	return end == len(body) || (after != '@' && after != '#')
+}
+
+// touchesAny reports whether loc overlaps or directly borders any of the
+// ranges.
+func touchesAny(loc []int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if loc[0] <= r[1] && r[0] <= loc[1] {
+			return true
+		}
+	}
+	return false
+}
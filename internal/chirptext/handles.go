@@ -0,0 +1,52 @@
+package chirptext
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	MinHandleLength = 3
+	MaxHandleLength = 15
+)
+
+// ErrInvalidHandle -
+var ErrInvalidHandle = fmt.Errorf("handles are %d to %d letters, digits or underscores", MinHandleLength, MaxHandleLength)
+
+// ErrReservedHandle -
+var ErrReservedHandle = errors.New("handle is reserved")
+
+var handleRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// reservedHandles can't be taken by anyone, since they could pass for the
+// service itself or clash with pages of a client.
+var reservedHandles = map[string]bool{
+	"2fa": true, "about": true, "api": true, "app": true, "everyone": true,
+	"explore": true, "help": true, "here": true, "home": true, "login": true,
+	"logout": true, "me": true, "mod": true, "moderator": true, "null": true,
+	"register": true, "root": true, "search": true, "security": true,
+	"settings": true, "signup": true, "staff": true, "support": true,
+	"system": true, "timeline": true, "undefined": true,
+}
+
+// reservedHandleParts can't appear anywhere in a handle.
+var reservedHandleParts = []string{"admin", "chirpy"}
+
+// CheckHandle checks that handle, without the @, is one users may choose.
+func CheckHandle(handle string) error {
+	if len(handle) < MinHandleLength || len(handle) > MaxHandleLength || !handleRegex.MatchString(handle) {
+		return ErrInvalidHandle
+	}
+	lower := strings.ToLower(handle)
+	if reservedHandles[lower] {
+		return ErrReservedHandle
+	}
+	for _, part := range reservedHandleParts {
+		if strings.Contains(lower, part) {
+			return ErrReservedHandle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: chirp_entities.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createChirpHashtag = `-- name: CreateChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, tag, start_index, end_index)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+`
+
+type CreateChirpHashtagParams struct {
+	ChirpID    uuid.UUID
+	Tag        string
+	StartIndex int32
+	EndIndex   int32
+}
+
+func (q *Queries) CreateChirpHashtag(ctx context.Context, arg CreateChirpHashtagParams) error {
+	_, err := q.db.ExecContext(ctx, createChirpHashtag,
+		arg.ChirpID,
+		arg.Tag,
+		arg.StartIndex,
+		arg.EndIndex,
+	)
+	return err
+}
+
+const createChirpMention = `-- name: CreateChirpMention :exec
+INSERT INTO chirp_mentions (chirp_id, user_id, handle, start_index, end_index)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+`
+
+type CreateChirpMentionParams struct {
+	ChirpID    uuid.UUID
+	UserID     uuid.UUID
+	Handle     string
+	StartIndex int32
+	EndIndex   int32
+}
+
+func (q *Queries) CreateChirpMention(ctx context.Context, arg CreateChirpMentionParams) error {
+	_, err := q.db.ExecContext(ctx, createChirpMention,
+		arg.ChirpID,
+		arg.UserID,
+		arg.Handle,
+		arg.StartIndex,
+		arg.EndIndex,
+	)
+	return err
+}
+
+const deleteChirpHashtags = `-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1
+`
+
+func (q *Queries) DeleteChirpHashtags(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteChirpHashtags, chirpID)
+	return err
+}
+
+const deleteChirpMentions = `-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1
+`
+
+func (q *Queries) DeleteChirpMentions(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteChirpMentions, chirpID)
+	return err
+}
+
+const getChirpHashtags = `-- name: GetChirpHashtags :many
+SELECT chirp_id, tag, start_index, end_index FROM chirp_hashtags
+WHERE chirp_id = ANY($1::uuid[])
+ORDER BY chirp_id, start_index
+`
+
+func (q *Queries) GetChirpHashtags(ctx context.Context, chirpIds []uuid.UUID) ([]ChirpHashtag, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpHashtags, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChirpHashtag
+	for rows.Next() {
+		var i ChirpHashtag
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.Tag,
+			&i.StartIndex,
+			&i.EndIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpMentions = `-- name: GetChirpMentions :many
+SELECT chirp_id, user_id, handle, start_index, end_index FROM chirp_mentions
+WHERE chirp_id = ANY($1::uuid[])
+ORDER BY chirp_id, start_index
+`
+
+func (q *Queries) GetChirpMentions(ctx context.Context, chirpIds []uuid.UUID) ([]ChirpMention, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpMentions, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChirpMention
+	for rows.Next() {
+		var i ChirpMention
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.UserID,
+			&i.Handle,
+			&i.StartIndex,
+			&i.EndIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsMentioningUser = `-- name: GetChirpsMentioningUser :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps
+WHERE id IN (SELECT m.chirp_id FROM chirp_mentions m WHERE m.user_id = $1)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetChirpsMentioningUserParams struct {
+	UserID         uuid.UUID
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+func (q *Queries) GetChirpsMentioningUser(ctx context.Context, arg GetChirpsMentioningUserParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsMentioningUser,
+		arg.UserID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyToID,
+			&i.ReplyCount,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsWithHashtag = `-- name: GetChirpsWithHashtag :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps
+WHERE id IN (SELECT h.chirp_id FROM chirp_hashtags h WHERE h.tag = $1)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetChirpsWithHashtagParams struct {
+	Tag            string
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+func (q *Queries) GetChirpsWithHashtag(ctx context.Context, arg GetChirpsWithHashtagParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsWithHashtag,
+		arg.Tag,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyToID,
+			&i.ReplyCount,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
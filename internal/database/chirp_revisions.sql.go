@@ -0,0 +1,79 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: chirp_revisions.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createChirpRevision = `-- name: CreateChirpRevision :one
+INSERT INTO chirp_revisions (id, chirp_id, body, created_at, replaced_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+RETURNING id, chirp_id, body, created_at, replaced_at
+`
+
+type CreateChirpRevisionParams struct {
+	ChirpID   uuid.UUID
+	Body      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateChirpRevision(ctx context.Context, arg CreateChirpRevisionParams) (ChirpRevision, error) {
+	row := q.db.QueryRowContext(ctx, createChirpRevision, arg.ChirpID, arg.Body, arg.CreatedAt)
+	var i ChirpRevision
+	err := row.Scan(
+		&i.ID,
+		&i.ChirpID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.ReplacedAt,
+	)
+	return i, err
+}
+
+const getChirpRevisions = `-- name: GetChirpRevisions :many
+SELECT id, chirp_id, body, created_at, replaced_at FROM chirp_revisions
+WHERE chirp_id = $1
+ORDER BY replaced_at, id
+`
+
+func (q *Queries) GetChirpRevisions(ctx context.Context, chirpID uuid.UUID) ([]ChirpRevision, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpRevisions, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChirpRevision
+	for rows.Next() {
+		var i ChirpRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChirpID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.ReplacedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,29 +7,84 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to_id, kind, original_chirp_id)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body            string
+	UserID          uuid.UUID
+	InReplyToID     uuid.NullUUID
+	Kind            ChirpKind
+	OriginalChirpID uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.InReplyToID,
+		arg.Kind,
+		arg.OriginalChirpID,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyToID,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+	)
+	return i, err
+}
+
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, kind, original_chirp_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    '',
+    $1,
+    'rechirp',
+    $2
+)
+ON CONFLICT (user_id, original_chirp_id) WHERE kind = 'rechirp' DO NOTHING
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count
+`
+
+type CreateRechirpParams struct {
+	UserID          uuid.UUID
+	OriginalChirpID uuid.NullUUID
+}
+
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp, arg.UserID, arg.OriginalChirpID)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,13 +92,52 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyToID,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
 	)
 	return i, err
 }
 
+const decrementQuoteCount = `-- name: DecrementQuoteCount :exec
+UPDATE chirps SET quote_count = quote_count - 1
+WHERE id = $1 AND quote_count > 0
+`
+
+func (q *Queries) DecrementQuoteCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, decrementQuoteCount, id)
+	return err
+}
+
+const decrementRechirpCount = `-- name: DecrementRechirpCount :exec
+UPDATE chirps SET rechirp_count = rechirp_count - 1
+WHERE id = $1 AND rechirp_count > 0
+`
+
+func (q *Queries) DecrementRechirpCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, decrementRechirpCount, id)
+	return err
+}
+
+const decrementReplyCount = `-- name: DecrementReplyCount :exec
+UPDATE chirps SET reply_count = reply_count - 1
+WHERE id = $1 AND reply_count > 0
+`
+
+func (q *Queries) DecrementReplyCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, decrementReplyCount, id)
+	return err
+}
+
 const deleteChirp = `-- name: DeleteChirp :exec
 DELETE FROM chirps
 WHERE id = $1
+OR (original_chirp_id = $1 AND kind = 'rechirp')
 `
 
 func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
@@ -52,7 +146,7 @@ func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
 }
 
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE id=$1
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps WHERE id=$1
 `
 
 func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -64,16 +158,391 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyToID,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+	)
+	return i, err
+}
+
+const getChirpAncestors = `-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT parent.id, parent.in_reply_to_id, 1 AS depth FROM chirps parent
+    WHERE parent.id = (SELECT child.in_reply_to_id FROM chirps child WHERE child.id = $1)
+    UNION ALL
+    SELECT parent.id, parent.in_reply_to_id, ancestors.depth + 1 FROM chirps parent
+    JOIN ancestors ON parent.id = ancestors.in_reply_to_id
+    WHERE ancestors.depth < $2::integer
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to_id, chirps.reply_count, chirps.like_count, chirps.kind, chirps.original_chirp_id, chirps.rechirp_count, chirps.quote_count, ancestors.depth FROM ancestors
+JOIN chirps ON chirps.id = ancestors.id
+ORDER BY ancestors.depth DESC
+`
+
+type GetChirpAncestorsParams struct {
+	ID       uuid.UUID
+	MaxDepth int32
+}
+
+type GetChirpAncestorsRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+func (q *Queries) GetChirpAncestors(ctx context.Context, arg GetChirpAncestorsParams) ([]GetChirpAncestorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpAncestors, arg.ID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpAncestorsRow
+	for rows.Next() {
+		var i GetChirpAncestorsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyToID,
+			&i.Chirp.ReplyCount,
+			&i.Chirp.LikeCount,
+			&i.Chirp.Kind,
+			&i.Chirp.OriginalChirpID,
+			&i.Chirp.RechirpCount,
+			&i.Chirp.QuoteCount,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpDescendants = `-- name: GetChirpDescendants :many
+WITH RECURSIVE descendants AS (
+    SELECT reply.id, 1 AS depth FROM chirps reply
+    WHERE reply.in_reply_to_id = $2::uuid
+    UNION ALL
+    SELECT reply.id, descendants.depth + 1 FROM chirps reply
+    JOIN descendants ON reply.in_reply_to_id = descendants.id
+    WHERE descendants.depth < $3::integer
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to_id, chirps.reply_count, chirps.like_count, chirps.kind, chirps.original_chirp_id, chirps.rechirp_count, chirps.quote_count, descendants.depth FROM descendants
+JOIN chirps ON chirps.id = descendants.id
+ORDER BY descendants.depth, chirps.created_at, chirps.id
+LIMIT $1
+`
+
+type GetChirpDescendantsParams struct {
+	Limit    int32
+	ID       uuid.UUID
+	MaxDepth int32
+}
+
+type GetChirpDescendantsRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+func (q *Queries) GetChirpDescendants(ctx context.Context, arg GetChirpDescendantsParams) ([]GetChirpDescendantsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpDescendants, arg.Limit, arg.ID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpDescendantsRow
+	for rows.Next() {
+		var i GetChirpDescendantsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyToID,
+			&i.Chirp.ReplyCount,
+			&i.Chirp.LikeCount,
+			&i.Chirp.Kind,
+			&i.Chirp.OriginalChirpID,
+			&i.Chirp.RechirpCount,
+			&i.Chirp.QuoteCount,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps WHERE id=$1
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyToID,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+	)
+	return i, err
+}
+
+const getChirpsAsc = `-- name: GetChirpsAsc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps
+WHERE ($1::uuid IS NULL OR user_id = $1::uuid)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) > ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type GetChirpsAscParams struct {
+	AuthorID       uuid.NullUUID
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+func (q *Queries) GetChirpsAsc(ctx context.Context, arg GetChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsAsc,
+		arg.AuthorID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyToID,
+			&i.ReplyCount,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyToID,
+			&i.ReplyCount,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsDesc = `-- name: GetChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps
+WHERE ($1::uuid IS NULL OR user_id = $1::uuid)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetChirpsDescParams struct {
+	AuthorID       uuid.NullUUID
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsDesc,
+		arg.AuthorID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyToID,
+			&i.ReplyCount,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRechirp = `-- name: GetRechirp :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps
+WHERE user_id = $1 AND original_chirp_id = $2 AND kind = 'rechirp'
+`
+
+type GetRechirpParams struct {
+	UserID          uuid.UUID
+	OriginalChirpID uuid.NullUUID
+}
+
+func (q *Queries) GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getRechirp, arg.UserID, arg.OriginalChirpID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyToID,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
 	)
 	return i, err
 }
 
-const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps ORDER BY created_at
+const getTimeline = `-- name: GetTimeline :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count FROM chirps
+WHERE (
+    user_id = $1
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1)
+)
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetTimelineParams struct {
+	UserID         uuid.UUID
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+func (q *Queries) GetTimeline(ctx context.Context, arg GetTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getTimeline,
+		arg.UserID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +556,122 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyToID,
+			&i.ReplyCount,
+			&i.LikeCount,
+			&i.Kind,
+			&i.OriginalChirpID,
+			&i.RechirpCount,
+			&i.QuoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementQuoteCount = `-- name: IncrementQuoteCount :exec
+UPDATE chirps SET quote_count = quote_count + 1
+WHERE id = $1
+`
+
+func (q *Queries) IncrementQuoteCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, incrementQuoteCount, id)
+	return err
+}
+
+const incrementRechirpCount = `-- name: IncrementRechirpCount :exec
+UPDATE chirps SET rechirp_count = rechirp_count + 1
+WHERE id = $1
+`
+
+func (q *Queries) IncrementRechirpCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, incrementRechirpCount, id)
+	return err
+}
+
+const incrementReplyCount = `-- name: IncrementReplyCount :exec
+UPDATE chirps SET reply_count = reply_count + 1
+WHERE id = $1
+`
+
+func (q *Queries) IncrementReplyCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, incrementReplyCount, id)
+	return err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT
+    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to_id, chirps.reply_count, chirps.like_count, chirps.kind, chirps.original_chirp_id, chirps.rechirp_count, chirps.quote_count,
+    ts_rank_cd(chirps.search_vector, query)::real AS rank,
+    ts_headline('english', chirps.body, query, $1::text)::text AS snippet
+FROM chirps, to_tsquery('english', $2::text) AS query
+WHERE chirps.search_vector @@ query
+AND ($3::uuid IS NULL OR chirps.user_id = $3::uuid)
+ORDER BY
+    CASE WHEN $4::text = 'asc' THEN chirps.created_at END ASC,
+    CASE WHEN $4::text = 'desc' THEN chirps.created_at END DESC,
+    rank DESC,
+    chirps.id DESC
+LIMIT $6
+OFFSET $5
+`
+
+type SearchChirpsParams struct {
+	HeadlineOptions string
+	Query           string
+	AuthorID        uuid.NullUUID
+	Sort            string
+	Offset          int32
+	Limit           int32
+}
+
+type SearchChirpsRow struct {
+	Chirp   Chirp
+	Rank    float32
+	Snippet string
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.HeadlineOptions,
+		arg.Query,
+		arg.AuthorID,
+		arg.Sort,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyToID,
+			&i.Chirp.ReplyCount,
+			&i.Chirp.LikeCount,
+			&i.Chirp.Kind,
+			&i.Chirp.OriginalChirpID,
+			&i.Chirp.RechirpCount,
+			&i.Chirp.QuoteCount,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -100,3 +685,35 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps SET body = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count
+`
+
+type UpdateChirpBodyParams struct {
+	ID   uuid.UUID
+	Body string
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyToID,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+	)
+	return i, err
+}
@@ -0,0 +1,106 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: email_verification_tokens.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countRecentEmailVerificationTokens = `-- name: CountRecentEmailVerificationTokens :one
+SELECT COUNT(*) FROM email_verification_tokens
+WHERE user_id = $1
+AND created_at > $2::timestamp
+`
+
+type CountRecentEmailVerificationTokensParams struct {
+	UserID uuid.UUID
+	Since  time.Time
+}
+
+func (q *Queries) CountRecentEmailVerificationTokens(ctx context.Context, arg CountRecentEmailVerificationTokensParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecentEmailVerificationTokens, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createEmailVerificationToken = `-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (token_hash, user_id, email, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    NOW(),
+    $4
+)
+`
+
+type CreateEmailVerificationTokenParams struct {
+	TokenHash string
+	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createEmailVerificationToken,
+		arg.TokenHash,
+		arg.UserID,
+		arg.Email,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const getEmailVerificationTokenForUpdate = `-- name: GetEmailVerificationTokenForUpdate :one
+SELECT token_hash, user_id, email, created_at, expires_at, used_at FROM email_verification_tokens
+WHERE token_hash = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetEmailVerificationTokenForUpdate(ctx context.Context, tokenHash string) (EmailVerificationToken, error) {
+	row := q.db.QueryRowContext(ctx, getEmailVerificationTokenForUpdate, tokenHash)
+	var i EmailVerificationToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UsedAt,
+	)
+	return i, err
+}
+
+const getPendingEmail = `-- name: GetPendingEmail :one
+SELECT email FROM email_verification_tokens
+WHERE user_id = $1
+AND used_at IS NULL
+AND expires_at > NOW()
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetPendingEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	row := q.db.QueryRowContext(ctx, getPendingEmail, userID)
+	var email string
+	err := row.Scan(&email)
+	return email, err
+}
+
+const useEmailVerificationTokens = `-- name: UseEmailVerificationTokens :exec
+UPDATE email_verification_tokens SET used_at = NOW()
+WHERE user_id = $1
+AND used_at IS NULL
+`
+
+func (q *Queries) UseEmailVerificationTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, useEmailVerificationTokens, userID)
+	return err
+}
@@ -0,0 +1,173 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: follows.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countFollowers = `-- name: CountFollowers :one
+SELECT COUNT(*) FROM follows WHERE followee_id = $1
+`
+
+func (q *Queries) CountFollowers(ctx context.Context, followeeID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFollowers, followeeID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countFollowing = `-- name: CountFollowing :one
+SELECT COUNT(*) FROM follows WHERE follower_id = $1
+`
+
+func (q *Queries) CountFollowing(ctx context.Context, followerID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFollowing, followerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const followUser = `-- name: FollowUser :exec
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING
+`
+
+type FollowUserParams struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+}
+
+func (q *Queries) FollowUser(ctx context.Context, arg FollowUserParams) error {
+	_, err := q.db.ExecContext(ctx, followUser, arg.FollowerID, arg.FolloweeID)
+	return err
+}
+
+const getFollowers = `-- name: GetFollowers :many
+SELECT follower_id AS user_id, created_at FROM follows
+WHERE followee_id = $1
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, follower_id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at DESC, follower_id DESC
+LIMIT $4
+`
+
+type GetFollowersParams struct {
+	UserID         uuid.UUID
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+type GetFollowersRow struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetFollowers(ctx context.Context, arg GetFollowersParams) ([]GetFollowersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowers,
+		arg.UserID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowersRow
+	for rows.Next() {
+		var i GetFollowersRow
+		if err := rows.Scan(&i.UserID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowing = `-- name: GetFollowing :many
+SELECT followee_id AS user_id, created_at FROM follows
+WHERE follower_id = $1
+AND (
+    $2::timestamp IS NULL
+    OR (created_at, followee_id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY created_at DESC, followee_id DESC
+LIMIT $4
+`
+
+type GetFollowingParams struct {
+	UserID         uuid.UUID
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+type GetFollowingRow struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetFollowing(ctx context.Context, arg GetFollowingParams) ([]GetFollowingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowing,
+		arg.UserID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowingRow
+	for rows.Next() {
+		var i GetFollowingRow
+		if err := rows.Scan(&i.UserID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unfollowUser = `-- name: UnfollowUser :exec
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2
+`
+
+type UnfollowUserParams struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+}
+
+func (q *Queries) UnfollowUser(ctx context.Context, arg UnfollowUserParams) error {
+	_, err := q.db.ExecContext(ctx, unfollowUser, arg.FollowerID, arg.FolloweeID)
+	return err
+}
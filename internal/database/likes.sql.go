@@ -0,0 +1,211 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: likes.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const decrementLikeCount = `-- name: DecrementLikeCount :one
+UPDATE chirps SET like_count = GREATEST(like_count - 1, 0)
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count
+`
+
+func (q *Queries) DecrementLikeCount(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, decrementLikeCount, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyToID,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+	)
+	return i, err
+}
+
+const getLikedChirpIDs = `-- name: GetLikedChirpIDs :many
+SELECT chirp_id FROM likes
+WHERE user_id = $1
+AND chirp_id = ANY($2::uuid[])
+`
+
+type GetLikedChirpIDsParams struct {
+	UserID   uuid.UUID
+	ChirpIds []uuid.UUID
+}
+
+func (q *Queries) GetLikedChirpIDs(ctx context.Context, arg GetLikedChirpIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getLikedChirpIDs, arg.UserID, pq.Array(arg.ChirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var chirp_id uuid.UUID
+		if err := rows.Scan(&chirp_id); err != nil {
+			return nil, err
+		}
+		items = append(items, chirp_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserLikes = `-- name: GetUserLikes :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to_id, chirps.reply_count, chirps.like_count, chirps.kind, chirps.original_chirp_id, chirps.rechirp_count, chirps.quote_count, likes.created_at AS liked_at FROM likes
+JOIN chirps ON chirps.id = likes.chirp_id
+WHERE likes.user_id = $1
+AND (
+    $2::timestamp IS NULL
+    OR (likes.created_at, likes.chirp_id) < ($2::timestamp, $3::uuid)
+)
+ORDER BY likes.created_at DESC, likes.chirp_id DESC
+LIMIT $4
+`
+
+type GetUserLikesParams struct {
+	UserID         uuid.UUID
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+type GetUserLikesRow struct {
+	Chirp   Chirp
+	LikedAt time.Time
+}
+
+func (q *Queries) GetUserLikes(ctx context.Context, arg GetUserLikesParams) ([]GetUserLikesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserLikes,
+		arg.UserID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserLikesRow
+	for rows.Next() {
+		var i GetUserLikesRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyToID,
+			&i.Chirp.ReplyCount,
+			&i.Chirp.LikeCount,
+			&i.Chirp.Kind,
+			&i.Chirp.OriginalChirpID,
+			&i.Chirp.RechirpCount,
+			&i.Chirp.QuoteCount,
+			&i.LikedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementLikeCount = `-- name: IncrementLikeCount :one
+UPDATE chirps SET like_count = like_count + 1
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, search_vector, in_reply_to_id, reply_count, like_count, kind, original_chirp_id, rechirp_count, quote_count
+`
+
+func (q *Queries) IncrementLikeCount(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, incrementLikeCount, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyToID,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.Kind,
+		&i.OriginalChirpID,
+		&i.RechirpCount,
+		&i.QuoteCount,
+	)
+	return i, err
+}
+
+const likeChirp = `-- name: LikeChirp :execrows
+INSERT INTO likes (user_id, chirp_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING
+`
+
+type LikeChirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) LikeChirp(ctx context.Context, arg LikeChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, likeChirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unlikeChirp = `-- name: UnlikeChirp :execrows
+DELETE FROM likes
+WHERE user_id = $1 AND chirp_id = $2
+`
+
+type UnlikeChirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) UnlikeChirp(ctx context.Context, arg UnlikeChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unlikeChirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,213 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: login_throttling.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const attemptLogin = `-- name: AttemptLogin :one
+INSERT INTO login_failures (key, failures, last_failure_at)
+VALUES (
+    $1,
+    1,
+    $2::timestamp
+)
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE
+        WHEN login_failures.last_failure_at < $3::timestamp THEN 1
+        ELSE login_failures.failures + 1
+    END,
+    last_failure_at = EXCLUDED.last_failure_at,
+    previous_failure_at = login_failures.last_failure_at
+RETURNING key, failures, last_failure_at, previous_failure_at
+`
+
+type AttemptLoginParams struct {
+	Key   string
+	Now   time.Time
+	Since time.Time
+}
+
+func (q *Queries) AttemptLogin(ctx context.Context, arg AttemptLoginParams) (LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, attemptLogin, arg.Key, arg.Now, arg.Since)
+	var i LoginFailure
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LastFailureAt,
+		&i.PreviousFailureAt,
+	)
+	return i, err
+}
+
+const createLoginLockout = `-- name: CreateLoginLockout :exec
+INSERT INTO login_lockouts (id, email, user_id, ip_address, failures, locked_until, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    NOW()
+)
+`
+
+type CreateLoginLockoutParams struct {
+	Email       string
+	UserID      uuid.NullUUID
+	IpAddress   string
+	Failures    int32
+	LockedUntil time.Time
+}
+
+func (q *Queries) CreateLoginLockout(ctx context.Context, arg CreateLoginLockoutParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginLockout,
+		arg.Email,
+		arg.UserID,
+		arg.IpAddress,
+		arg.Failures,
+		arg.LockedUntil,
+	)
+	return err
+}
+
+const getLoginFailures = `-- name: GetLoginFailures :one
+SELECT key, failures, last_failure_at, previous_failure_at FROM login_failures
+WHERE key = $1
+`
+
+func (q *Queries) GetLoginFailures(ctx context.Context, key string) (LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, getLoginFailures, key)
+	var i LoginFailure
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LastFailureAt,
+		&i.PreviousFailureAt,
+	)
+	return i, err
+}
+
+const getUnreviewedLoginLockouts = `-- name: GetUnreviewedLoginLockouts :many
+SELECT id, email, user_id, ip_address, failures, locked_until, created_at, reviewed_at FROM login_lockouts
+WHERE reviewed_at IS NULL
+AND (
+    $1::timestamp IS NULL
+    OR (created_at, id) > ($1::timestamp, $2::uuid)
+)
+ORDER BY created_at, id
+LIMIT $3
+`
+
+type GetUnreviewedLoginLockoutsParams struct {
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+func (q *Queries) GetUnreviewedLoginLockouts(ctx context.Context, arg GetUnreviewedLoginLockoutsParams) ([]LoginLockout, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreviewedLoginLockouts, arg.AfterCreatedAt, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginLockout
+	for rows.Next() {
+		var i LoginLockout
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.UserID,
+			&i.IpAddress,
+			&i.Failures,
+			&i.LockedUntil,
+			&i.CreatedAt,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pruneLoginFailures = `-- name: PruneLoginFailures :execrows
+DELETE FROM login_failures
+WHERE last_failure_at < $1
+`
+
+func (q *Queries) PruneLoginFailures(ctx context.Context, lastFailureAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneLoginFailures, lastFailureAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const refundLoginAttempt = `-- name: RefundLoginAttempt :exec
+UPDATE login_failures SET
+    failures = GREATEST(failures - 1, 0),
+    last_failure_at = CASE
+        WHEN last_failure_at = $1::timestamp THEN $2::timestamp
+        ELSE last_failure_at
+    END
+WHERE key = $3
+`
+
+type RefundLoginAttemptParams struct {
+	AttemptedAt       time.Time
+	PreviousFailureAt time.Time
+	Key               string
+}
+
+func (q *Queries) RefundLoginAttempt(ctx context.Context, arg RefundLoginAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, refundLoginAttempt, arg.AttemptedAt, arg.PreviousFailureAt, arg.Key)
+	return err
+}
+
+const resetLoginFailures = `-- name: ResetLoginFailures :exec
+DELETE FROM login_failures
+WHERE key = $1
+`
+
+func (q *Queries) ResetLoginFailures(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, resetLoginFailures, key)
+	return err
+}
+
+const reviewLoginLockout = `-- name: ReviewLoginLockout :one
+UPDATE login_lockouts SET reviewed_at = NOW()
+WHERE id = $1
+RETURNING id, email, user_id, ip_address, failures, locked_until, created_at, reviewed_at
+`
+
+func (q *Queries) ReviewLoginLockout(ctx context.Context, id uuid.UUID) (LoginLockout, error) {
+	row := q.db.QueryRowContext(ctx, reviewLoginLockout, id)
+	var i LoginLockout
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserID,
+		&i.IpAddress,
+		&i.Failures,
+		&i.LockedUntil,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,204 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: media.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const attachMedia = `-- name: AttachMedia :exec
+INSERT INTO chirp_media (chirp_id, media_id, position)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+`
+
+type AttachMediaParams struct {
+	ChirpID  uuid.UUID
+	MediaID  uuid.UUID
+	Position int32
+}
+
+func (q *Queries) AttachMedia(ctx context.Context, arg AttachMediaParams) error {
+	_, err := q.db.ExecContext(ctx, attachMedia, arg.ChirpID, arg.MediaID, arg.Position)
+	return err
+}
+
+const createMedia = `-- name: CreateMedia :one
+INSERT INTO media (id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key, thumbnail_content_type)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key, thumbnail_content_type
+`
+
+type CreateMediaParams struct {
+	UserID               uuid.UUID
+	ContentType          string
+	SizeBytes            int32
+	Width                int32
+	Height               int32
+	StorageKey           string
+	ThumbnailKey         string
+	ThumbnailContentType string
+}
+
+func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (Medium, error) {
+	row := q.db.QueryRowContext(ctx, createMedia,
+		arg.UserID,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Width,
+		arg.Height,
+		arg.StorageKey,
+		arg.ThumbnailKey,
+		arg.ThumbnailContentType,
+	)
+	var i Medium
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.ThumbnailContentType,
+	)
+	return i, err
+}
+
+const getAttachableMedia = `-- name: GetAttachableMedia :many
+SELECT media.id, media.created_at, media.user_id, media.content_type, media.size_bytes, media.width, media.height, media.storage_key, media.thumbnail_key, media.thumbnail_content_type FROM media
+LEFT JOIN chirp_media ON chirp_media.media_id = media.id
+WHERE media.id = ANY($1::uuid[])
+AND media.user_id = $2
+AND chirp_media.media_id IS NULL
+`
+
+type GetAttachableMediaParams struct {
+	Ids    []uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetAttachableMedia(ctx context.Context, arg GetAttachableMediaParams) ([]Medium, error) {
+	rows, err := q.db.QueryContext(ctx, getAttachableMedia, pq.Array(arg.Ids), arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Medium
+	for rows.Next() {
+		var i Medium
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+			&i.ThumbnailContentType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMedia = `-- name: GetMedia :one
+SELECT id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key, thumbnail_content_type FROM media WHERE id = $1
+`
+
+func (q *Queries) GetMedia(ctx context.Context, id uuid.UUID) (Medium, error) {
+	row := q.db.QueryRowContext(ctx, getMedia, id)
+	var i Medium
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.ThumbnailContentType,
+	)
+	return i, err
+}
+
+const getMediaForChirps = `-- name: GetMediaForChirps :many
+SELECT chirp_media.chirp_id, media.id, media.created_at, media.user_id, media.content_type, media.size_bytes, media.width, media.height, media.storage_key, media.thumbnail_key, media.thumbnail_content_type FROM chirp_media
+JOIN media ON media.id = chirp_media.media_id
+WHERE chirp_media.chirp_id = ANY($1::uuid[])
+ORDER BY chirp_media.chirp_id, chirp_media.position
+`
+
+type GetMediaForChirpsRow struct {
+	ChirpID uuid.UUID
+	Medium  Medium
+}
+
+func (q *Queries) GetMediaForChirps(ctx context.Context, chirpIds []uuid.UUID) ([]GetMediaForChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMediaForChirps, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMediaForChirpsRow
+	for rows.Next() {
+		var i GetMediaForChirpsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.Medium.ID,
+			&i.Medium.CreatedAt,
+			&i.Medium.UserID,
+			&i.Medium.ContentType,
+			&i.Medium.SizeBytes,
+			&i.Medium.Width,
+			&i.Medium.Height,
+			&i.Medium.StorageKey,
+			&i.Medium.ThumbnailKey,
+			&i.Medium.ThumbnailContentType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -6,33 +6,262 @@ package database
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ChirpKind string
+
+const (
+	ChirpKindChirp   ChirpKind = "chirp"
+	ChirpKindRechirp ChirpKind = "rechirp"
+	ChirpKindQuote   ChirpKind = "quote"
+)
+
+func (e *ChirpKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ChirpKind(s)
+	case string:
+		*e = ChirpKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ChirpKind: %T", src)
+	}
+	return nil
+}
+
+type NullChirpKind struct {
+	ChirpKind ChirpKind
+	Valid     bool // Valid is true if ChirpKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullChirpKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.ChirpKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ChirpKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullChirpKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ChirpKind), nil
+}
+
+type ModerationAction string
+
+const (
+	ModerationActionMask   ModerationAction = "mask"
+	ModerationActionReject ModerationAction = "reject"
+	ModerationActionFlag   ModerationAction = "flag"
+)
+
+func (e *ModerationAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ModerationAction(s)
+	case string:
+		*e = ModerationAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ModerationAction: %T", src)
+	}
+	return nil
+}
+
+type NullModerationAction struct {
+	ModerationAction ModerationAction
+	Valid            bool // Valid is true if ModerationAction is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullModerationAction) Scan(value interface{}) error {
+	if value == nil {
+		ns.ModerationAction, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ModerationAction.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullModerationAction) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ModerationAction), nil
+}
+
 type Chirp struct {
-	ID        uuid.UUID
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.UUID
+	SearchVector    interface{}
+	InReplyToID     uuid.NullUUID
+	ReplyCount      int32
+	LikeCount       int32
+	Kind            ChirpKind
+	OriginalChirpID uuid.NullUUID
+	RechirpCount    int32
+	QuoteCount      int32
+}
+
+type ChirpHashtag struct {
+	ChirpID    uuid.UUID
+	Tag        string
+	StartIndex int32
+	EndIndex   int32
+}
+
+type ChirpMedium struct {
+	ChirpID  uuid.UUID
+	MediaID  uuid.UUID
+	Position int32
+}
+
+type ChirpMention struct {
+	ChirpID    uuid.UUID
+	UserID     uuid.UUID
+	Handle     string
+	StartIndex int32
+	EndIndex   int32
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	Body       string
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+}
+
+type EmailVerificationToken struct {
+	TokenHash string
+	UserID    uuid.UUID
+	Email     string
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Like struct {
 	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
 }
 
-type RefreshToken struct {
-	Token     string
+type LoginFailure struct {
+	Key               string
+	Failures          int32
+	LastFailureAt     time.Time
+	PreviousFailureAt sql.NullTime
+}
+
+type LoginLockout struct {
+	ID          uuid.UUID
+	Email       string
+	UserID      uuid.NullUUID
+	IpAddress   string
+	Failures    int32
+	LockedUntil time.Time
+	CreatedAt   time.Time
+	ReviewedAt  sql.NullTime
+}
+
+type Medium struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UserID               uuid.UUID
+	ContentType          string
+	SizeBytes            int32
+	Width                int32
+	Height               int32
+	StorageKey           string
+	ThumbnailKey         string
+	ThumbnailContentType string
+}
+
+type ModerationFlag struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	Rule       string
+	CreatedAt  time.Time
+	ReviewedAt sql.NullTime
+}
+
+type ModerationWord struct {
+	Word      string
+	Action    ModerationAction
 	CreatedAt time.Time
 	UpdatedAt time.Time
+}
+
+type PasswordResetToken struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID
 	UserID    uuid.UUID
+	CodeHash  string
+	CreatedAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type RefreshToken struct {
+	TokenHash        string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	UserID           uuid.UUID
+	ExpiresAt        time.Time
+	RevokedAt        sql.NullTime
+	FamilyID         uuid.UUID
+	ReplacedBy       sql.NullString
+	SessionStartedAt time.Time
+	LastUsedAt       time.Time
+	UserAgent        string
+	IpAddress        string
+	AccessTokenJti   sql.NullString
+}
+
+type RevokedAccessToken struct {
+	Jti       string
 	ExpiresAt time.Time
-	RevokedAt sql.NullTime
 }
 
 type User struct {
-	ID             uuid.UUID
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    bool
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Email           string
+	HashedPassword  string
+	IsChirpyRed     bool
+	Roles           []string
+	TotpSecret      sql.NullString
+	TotpEnabledAt   sql.NullTime
+	TotpLastStep    int64
+	EmailVerifiedAt sql.NullTime
+	Handle          string
+	DisplayName     string
+	Bio             string
+	AvatarMediaID   uuid.NullUUID
 }
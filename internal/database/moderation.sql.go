@@ -0,0 +1,174 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: moderation.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createModerationFlag = `-- name: CreateModerationFlag :exec
+INSERT INTO moderation_flags (id, chirp_id, rule, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+)
+`
+
+type CreateModerationFlagParams struct {
+	ChirpID uuid.UUID
+	Rule    string
+}
+
+func (q *Queries) CreateModerationFlag(ctx context.Context, arg CreateModerationFlagParams) error {
+	_, err := q.db.ExecContext(ctx, createModerationFlag, arg.ChirpID, arg.Rule)
+	return err
+}
+
+const deleteModerationWord = `-- name: DeleteModerationWord :execrows
+DELETE FROM moderation_words
+WHERE word = $1
+`
+
+func (q *Queries) DeleteModerationWord(ctx context.Context, word string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteModerationWord, word)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getModerationWords = `-- name: GetModerationWords :many
+SELECT word, action, created_at, updated_at FROM moderation_words
+ORDER BY word
+`
+
+func (q *Queries) GetModerationWords(ctx context.Context) ([]ModerationWord, error) {
+	rows, err := q.db.QueryContext(ctx, getModerationWords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModerationWord
+	for rows.Next() {
+		var i ModerationWord
+		if err := rows.Scan(
+			&i.Word,
+			&i.Action,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreviewedModerationFlags = `-- name: GetUnreviewedModerationFlags :many
+SELECT id, chirp_id, rule, created_at, reviewed_at FROM moderation_flags
+WHERE reviewed_at IS NULL
+AND (
+    $1::timestamp IS NULL
+    OR (created_at, id) > ($1::timestamp, $2::uuid)
+)
+ORDER BY created_at, id
+LIMIT $3
+`
+
+type GetUnreviewedModerationFlagsParams struct {
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	Limit          int32
+}
+
+func (q *Queries) GetUnreviewedModerationFlags(ctx context.Context, arg GetUnreviewedModerationFlagsParams) ([]ModerationFlag, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreviewedModerationFlags, arg.AfterCreatedAt, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModerationFlag
+	for rows.Next() {
+		var i ModerationFlag
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChirpID,
+			&i.Rule,
+			&i.CreatedAt,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reviewModerationFlag = `-- name: ReviewModerationFlag :one
+UPDATE moderation_flags SET reviewed_at = NOW()
+WHERE id = $1
+RETURNING id, chirp_id, rule, created_at, reviewed_at
+`
+
+func (q *Queries) ReviewModerationFlag(ctx context.Context, id uuid.UUID) (ModerationFlag, error) {
+	row := q.db.QueryRowContext(ctx, reviewModerationFlag, id)
+	var i ModerationFlag
+	err := row.Scan(
+		&i.ID,
+		&i.ChirpID,
+		&i.Rule,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
+
+const upsertModerationWord = `-- name: UpsertModerationWord :one
+INSERT INTO moderation_words (word, action, created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW()
+)
+ON CONFLICT (word) DO UPDATE
+SET action = EXCLUDED.action, updated_at = NOW()
+RETURNING word, action, created_at, updated_at
+`
+
+type UpsertModerationWordParams struct {
+	Word   string
+	Action ModerationAction
+}
+
+func (q *Queries) UpsertModerationWord(ctx context.Context, arg UpsertModerationWordParams) (ModerationWord, error) {
+	row := q.db.QueryRowContext(ctx, upsertModerationWord, arg.Word, arg.Action)
+	var i ModerationWord
+	err := row.Scan(
+		&i.Word,
+		&i.Action,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,82 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: password_reset_tokens.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countRecentPasswordResetTokens = `-- name: CountRecentPasswordResetTokens :one
+SELECT COUNT(*) FROM password_reset_tokens
+WHERE user_id = $1
+AND created_at > $2::timestamp
+`
+
+type CountRecentPasswordResetTokensParams struct {
+	UserID uuid.UUID
+	Since  time.Time
+}
+
+func (q *Queries) CountRecentPasswordResetTokens(ctx context.Context, arg CountRecentPasswordResetTokensParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecentPasswordResetTokens, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createPasswordResetToken = `-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, user_id, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    $3
+)
+`
+
+type CreatePasswordResetTokenParams struct {
+	TokenHash string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createPasswordResetToken, arg.TokenHash, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
+const getPasswordResetTokenForUpdate = `-- name: GetPasswordResetTokenForUpdate :one
+SELECT token_hash, user_id, created_at, expires_at, used_at FROM password_reset_tokens
+WHERE token_hash = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetPasswordResetTokenForUpdate(ctx context.Context, tokenHash string) (PasswordResetToken, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordResetTokenForUpdate, tokenHash)
+	var i PasswordResetToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UsedAt,
+	)
+	return i, err
+}
+
+const usePasswordResetTokens = `-- name: UsePasswordResetTokens :exec
+UPDATE password_reset_tokens SET used_at = NOW()
+WHERE user_id = $1
+AND used_at IS NULL
+`
+
+func (q *Queries) UsePasswordResetTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, usePasswordResetTokens, userID)
+	return err
+}
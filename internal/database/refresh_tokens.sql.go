@@ -13,76 +13,308 @@ import (
 )
 
 const createRefreshToken = `-- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at)
+INSERT INTO refresh_tokens (token_hash, created_at, updated_at, user_id, expires_at, family_id, session_started_at, last_used_at, user_agent, ip_address, access_token_jti)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
-    $3
+    $3,
+    $4,
+    $5,
+    NOW(),
+    $6,
+    $7,
+    $8::text
 )
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at
+RETURNING token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by, session_started_at, last_used_at, user_agent, ip_address, access_token_jti
 `
 
 type CreateRefreshTokenParams struct {
-	Token     string
-	UserID    uuid.UUID
-	ExpiresAt time.Time
+	TokenHash        string
+	UserID           uuid.UUID
+	ExpiresAt        time.Time
+	FamilyID         uuid.UUID
+	SessionStartedAt time.Time
+	UserAgent        string
+	IpAddress        string
+	AccessTokenJti   string
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, createRefreshToken, arg.Token, arg.UserID, arg.ExpiresAt)
+	row := q.db.QueryRowContext(ctx, createRefreshToken,
+		arg.TokenHash,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.FamilyID,
+		arg.SessionStartedAt,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.AccessTokenJti,
+	)
 	var i RefreshToken
 	err := row.Scan(
-		&i.Token,
+		&i.TokenHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ReplacedBy,
+		&i.SessionStartedAt,
+		&i.LastUsedAt,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.AccessTokenJti,
 	)
 	return i, err
 }
 
-const getUserFromRefreshToken = `-- name: GetUserFromRefreshToken :one
-SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_chirpy_red FROM users
-JOIN refresh_tokens ON users.id = refresh_tokens.user_id
-WHERE refresh_tokens.token = $1
+const getActiveSessions = `-- name: GetActiveSessions :many
+SELECT family_id, session_started_at, last_used_at, user_agent, ip_address FROM refresh_tokens
+WHERE user_id = $1
 AND revoked_at IS NULL
 AND expires_at > NOW()
+ORDER BY last_used_at DESC, family_id
+`
+
+type GetActiveSessionsRow struct {
+	FamilyID         uuid.UUID
+	SessionStartedAt time.Time
+	LastUsedAt       time.Time
+	UserAgent        string
+	IpAddress        string
+}
+
+func (q *Queries) GetActiveSessions(ctx context.Context, userID uuid.UUID) ([]GetActiveSessionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActiveSessionsRow
+	for rows.Next() {
+		var i GetActiveSessionsRow
+		if err := rows.Scan(
+			&i.FamilyID,
+			&i.SessionStartedAt,
+			&i.LastUsedAt,
+			&i.UserAgent,
+			&i.IpAddress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRefreshToken = `-- name: GetRefreshToken :one
+SELECT token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by, session_started_at, last_used_at, user_agent, ip_address, access_token_jti FROM refresh_tokens
+WHERE token_hash = $1
 `
 
-func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserFromRefreshToken, token)
-	var i User
+func (q *Queries) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshToken, tokenHash)
+	var i RefreshToken
 	err := row.Scan(
-		&i.ID,
+		&i.TokenHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Email,
-		&i.HashedPassword,
-		&i.IsChirpyRed,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ReplacedBy,
+		&i.SessionStartedAt,
+		&i.LastUsedAt,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.AccessTokenJti,
 	)
 	return i, err
 }
 
-const revokeRefreshToken = `-- name: RevokeRefreshToken :one
-UPDATE refresh_tokens SET revoked_at = NOW(),
-updated_at = NOW()
-WHERE token = $1
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at
+const getRefreshTokenForUpdate = `-- name: GetRefreshTokenForUpdate :one
+SELECT token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by, session_started_at, last_used_at, user_agent, ip_address, access_token_jti FROM refresh_tokens
+WHERE token_hash = $1
+FOR UPDATE
 `
 
-func (q *Queries) RevokeRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, revokeRefreshToken, token)
+func (q *Queries) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshTokenForUpdate, tokenHash)
 	var i RefreshToken
 	err := row.Scan(
-		&i.Token,
+		&i.TokenHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ReplacedBy,
+		&i.SessionStartedAt,
+		&i.LastUsedAt,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.AccessTokenJti,
 	)
 	return i, err
 }
+
+const getSessionAccessTokens = `-- name: GetSessionAccessTokens :many
+SELECT access_token_jti::text AS jti, created_at FROM refresh_tokens
+WHERE family_id = $1
+AND access_token_jti IS NOT NULL
+AND created_at > $2
+`
+
+type GetSessionAccessTokensParams struct {
+	FamilyID    uuid.UUID
+	IssuedAfter time.Time
+}
+
+type GetSessionAccessTokensRow struct {
+	Jti       string
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetSessionAccessTokens(ctx context.Context, arg GetSessionAccessTokensParams) ([]GetSessionAccessTokensRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSessionAccessTokens, arg.FamilyID, arg.IssuedAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSessionAccessTokensRow
+	for rows.Next() {
+		var i GetSessionAccessTokensRow
+		if err := rows.Scan(&i.Jti, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserAccessTokens = `-- name: GetUserAccessTokens :many
+SELECT access_token_jti::text AS jti, created_at FROM refresh_tokens
+WHERE user_id = $1
+AND access_token_jti IS NOT NULL
+AND created_at > $2
+AND ($3::uuid IS NULL OR family_id <> $3::uuid)
+`
+
+type GetUserAccessTokensParams struct {
+	UserID         uuid.UUID
+	IssuedAfter    time.Time
+	ExceptFamilyID uuid.NullUUID
+}
+
+type GetUserAccessTokensRow struct {
+	Jti       string
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetUserAccessTokens(ctx context.Context, arg GetUserAccessTokensParams) ([]GetUserAccessTokensRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserAccessTokens, arg.UserID, arg.IssuedAfter, arg.ExceptFamilyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserAccessTokensRow
+	for rows.Next() {
+		var i GetUserAccessTokensRow
+		if err := rows.Scan(&i.Jti, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE family_id = $1
+AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshTokenFamily, familyID)
+	return err
+}
+
+const revokeSession = `-- name: RevokeSession :execrows
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE family_id = $1
+AND user_id = $2
+AND revoked_at IS NULL
+`
+
+type RevokeSessionParams struct {
+	FamilyID uuid.UUID
+	UserID   uuid.UUID
+}
+
+func (q *Queries) RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeSession, arg.FamilyID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revokeUserSessions = `-- name: RevokeUserSessions :exec
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE user_id = $1
+AND revoked_at IS NULL
+AND ($2::uuid IS NULL OR family_id <> $2::uuid)
+`
+
+type RevokeUserSessionsParams struct {
+	UserID         uuid.UUID
+	ExceptFamilyID uuid.NullUUID
+}
+
+func (q *Queries) RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, revokeUserSessions, arg.UserID, arg.ExceptFamilyID)
+	return err
+}
+
+const rotateRefreshToken = `-- name: RotateRefreshToken :exec
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW(),
+replaced_by = $1::text
+WHERE token_hash = $2
+`
+
+type RotateRefreshTokenParams struct {
+	ReplacedBy string
+	TokenHash  string
+}
+
+func (q *Queries) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) error {
+	_, err := q.db.ExecContext(ctx, rotateRefreshToken, arg.ReplacedBy, arg.TokenHash)
+	return err
+}
@@ -0,0 +1,58 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: revoked_access_tokens.sql
+
+package database
+
+import (
+	"context"
+	"time"
+)
+
+const isAccessTokenRevoked = `-- name: IsAccessTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1 FROM revoked_access_tokens
+    WHERE jti = $1
+    AND expires_at > NOW()
+)
+`
+
+func (q *Queries) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isAccessTokenRevoked, jti)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const pruneRevokedAccessTokens = `-- name: PruneRevokedAccessTokens :execrows
+DELETE FROM revoked_access_tokens
+WHERE expires_at <= NOW()
+`
+
+func (q *Queries) PruneRevokedAccessTokens(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneRevokedAccessTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revokeAccessToken = `-- name: RevokeAccessToken :exec
+INSERT INTO revoked_access_tokens (jti, expires_at)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT (jti) DO UPDATE SET expires_at = GREATEST(revoked_access_tokens.expires_at, EXCLUDED.expires_at)
+`
+
+type RevokeAccessTokenParams struct {
+	Jti       string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) RevokeAccessToken(ctx context.Context, arg RevokeAccessTokenParams) error {
+	_, err := q.db.ExecContext(ctx, revokeAccessToken, arg.Jti, arg.ExpiresAt)
+	return err
+}
@@ -7,29 +7,33 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword string
+	Handle         string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Handle)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -38,12 +42,21 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		pq.Array(&i.Roles),
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -56,24 +69,245 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		pq.Array(&i.Roles),
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
 	)
 	return i, err
 }
 
-const updateUser = `-- name: UpdateUser :one
-UPDATE users SET email = $2, hashed_password = $3, updated_at = NOW()
+const getUserByHandle = `-- name: GetUserByHandle :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id FROM users WHERE LOWER(handle) = LOWER($1)
+`
+
+func (q *Queries) GetUserByHandle(ctx context.Context, lower string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByHandle, lower)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		pq.Array(&i.Roles),
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		pq.Array(&i.Roles),
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+	)
+	return i, err
+}
+
+const getUsersByHandles = `-- name: GetUsersByHandles :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id FROM users WHERE LOWER(handle) = ANY($1::text[])
+`
+
+func (q *Queries) GetUsersByHandles(ctx context.Context, handles []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByHandles, pq.Array(handles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			pq.Array(&i.Roles),
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.EmailVerifiedAt,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarMediaID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id FROM users WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			pq.Array(&i.Roles),
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.EmailVerifiedAt,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarMediaID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users SET hashed_password = $1
+WHERE id = $2
+AND hashed_password = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHash string
+	ID      uuid.UUID
+	OldHash string
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rehashUserPassword, arg.NewHash, arg.ID, arg.OldHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users SET hashed_password = $2, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id
 `
 
-type UpdateUserParams struct {
+type UpdateUserPasswordParams struct {
 	ID             uuid.UUID
-	Email          string
 	HashedPassword string
 }
 
-func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUser, arg.ID, arg.Email, arg.HashedPassword)
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		pq.Array(&i.Roles),
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users SET
+    handle = COALESCE($1, handle),
+    display_name = COALESCE($2, display_name),
+    bio = COALESCE($3, bio),
+    avatar_media_id = CASE
+        WHEN $4::bool THEN $5::uuid
+        ELSE avatar_media_id
+    END,
+    updated_at = NOW()
+WHERE id = $6
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id
+`
+
+type UpdateUserProfileParams struct {
+	Handle        sql.NullString
+	DisplayName   sql.NullString
+	Bio           sql.NullString
+	SetAvatar     bool
+	AvatarMediaID uuid.NullUUID
+	ID            uuid.UUID
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.SetAvatar,
+		arg.AvatarMediaID,
+		arg.ID,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -82,6 +316,15 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		pq.Array(&i.Roles),
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
 	)
 	return i, err
 }
@@ -89,7 +332,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 const upgradeToChirpyRed = `-- name: UpgradeToChirpyRed :one
 UPDATE users SET is_chirpy_red = true, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id
 `
 
 func (q *Queries) UpgradeToChirpyRed(ctx context.Context, id uuid.UUID) (User, error) {
@@ -102,6 +345,49 @@ func (q *Queries) UpgradeToChirpyRed(ctx context.Context, id uuid.UUID) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		pq.Array(&i.Roles),
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users SET email = $2, email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, roles, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id
+`
+
+type VerifyUserEmailParams struct {
+	ID    uuid.UUID
+	Email string
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, verifyUserEmail, arg.ID, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		pq.Array(&i.Roles),
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
 	)
 	return i, err
 }
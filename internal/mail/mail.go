@@ -0,0 +1,172 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHeader -
+var ErrInvalidHeader = errors.New("invalid header value")
+
+// ErrInvalidAddress -
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// maxAddressLength is the longest address SMTP can deliver to (RFC 5321).
+const maxAddressLength = 254
+
+// NormalizeAddress checks that address is a bare email address such as
+// "user@example.com", without a display name or comments, and returns it
+// trimmed and lowercased so each address is stored only one way.
+func NormalizeAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" || len(address) > maxAddressLength {
+		return "", ErrInvalidAddress
+	}
+	parsed, err := netmail.ParseAddress(address)
+	if err != nil || parsed.Name != "" || parsed.Address != address {
+		return "", ErrInvalidAddress
+	}
+	// The domain needs at least one dot; "user@localhost" can't be reached
+	// from the internet.
+	at := strings.LastIndex(address, "@")
+	domain := address[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", ErrInvalidAddress
+	}
+	return CanonicalAddress(address), nil
+}
+
+// CanonicalAddress is the form addresses are stored and looked up in.
+// Unlike NormalizeAddress it doesn't check the address, so accounts made
+// before addresses were validated can still be found.
+func CanonicalAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Format renders msg as an RFC 5322 message from the given address. Header
+// values can't contain line breaks, so a user supplied address can't add
+// headers of its own.
+func Format(from string, msg Message, date time.Time) ([]byte, error) {
+	for _, value := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
+
+// SMTPMailer sends emails through an SMTP server, using STARTTLS when the
+// server offers it.
+type SMTPMailer struct {
+	// Addr is the host:port of the server.
+	Addr string
+	From string
+	// Auth is optional; PLAIN auth is only used over TLS or to localhost.
+	Auth smtp.Auth
+}
+
+// NewSMTPMailer makes an SMTPMailer that logs in with username and password
+// if username is set.
+func NewSMTPMailer(addr, from, username, password string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	m := &SMTPMailer{Addr: addr, From: from}
+	if username != "" {
+		m.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+// Send -
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := Format(m.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.Addr, m.Auth, m.From, []string{msg.To}, data)
+}
+
+// FileMailer writes every email to its own .eml file in a directory instead
+// of sending it, for development.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// NewFileMailer creates the directory if it doesn't exist yet.
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &FileMailer{Dir: dir, From: from}, nil
+}
+
+// Send -
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, err := Format(m.From, msg, now)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(m.Dir, now.Format("20060102T150405")+"-*.eml")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LogMailer logs emails instead of sending them, for development.
+type LogMailer struct {
+	Logger *log.Logger
+}
+
+// Send -
+func (m LogMailer) Send(ctx context.Context, msg Message) error {
+	logger := m.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,140 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		msg      Message
+		wantErr  error
+		contains []string
+	}{
+		{
+			name: "Plain message",
+			msg:  Message{To: "user@example.com", Subject: "Hello", Body: "line one\nline two"},
+			contains: []string{
+				"From: chirpy@example.com\r\n",
+				"To: user@example.com\r\n",
+				"Subject: Hello\r\n",
+				"Date: Tue, 02 Jan 2024 03:04:05 +0000\r\n",
+				"\r\n\r\nline one\r\nline two",
+			},
+		},
+		{
+			name:     "Non-ASCII subject is encoded",
+			msg:      Message{To: "user@example.com", Subject: "Héllo"},
+			contains: []string{"Subject: =?utf-8?q?H=C3=A9llo?=\r\n"},
+		},
+		{
+			name:    "Header injection in recipient",
+			msg:     Message{To: "user@example.com\r\nBcc: victim@example.com", Subject: "Hello"},
+			wantErr: ErrInvalidHeader,
+		},
+		{
+			name:    "Header injection in subject",
+			msg:     Message{To: "user@example.com", Subject: "Hello\nBcc: victim@example.com"},
+			wantErr: ErrInvalidHeader,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Format("chirpy@example.com", tt.msg, date)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Format() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Format() = %q, want it to contain %q", data, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer, err := NewFileMailer(dir, "chirpy@example.com")
+	if err != nil {
+		t.Fatalf("NewFileMailer() error = %v", err)
+	}
+
+	for range 2 {
+		err = mailer.Send(context.Background(), Message{To: "user@example.com", Subject: "Reset", Body: "token"})
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "To: user@example.com\r\n") {
+		t.Errorf("file = %q, want the message", data)
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := LogMailer{Logger: log.New(&buf, "", 0)}
+	err := mailer.Send(context.Background(), Message{To: "user@example.com", Subject: "Reset", Body: "token"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got, want := buf.String(), "Email to user@example.com: Reset\ntoken\n"; got != want {
+		t.Errorf("log = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		want    string
+		wantErr error
+	}{
+		{name: "Plain address", address: "user@example.com", want: "user@example.com"},
+		{name: "Lowercased and trimmed", address: "  User.Name+tag@Example.COM ", want: "user.name+tag@example.com"},
+		{name: "Empty", address: "", wantErr: ErrInvalidAddress},
+		{name: "No at sign", address: "user.example.com", wantErr: ErrInvalidAddress},
+		{name: "No domain", address: "user@", wantErr: ErrInvalidAddress},
+		{name: "Dotless domain", address: "user@localhost", wantErr: ErrInvalidAddress},
+		{name: "Trailing dot", address: "user@example.", wantErr: ErrInvalidAddress},
+		{name: "Display name", address: "User <user@example.com>", wantErr: ErrInvalidAddress},
+		{name: "Angle brackets", address: "<user@example.com>", wantErr: ErrInvalidAddress},
+		{name: "Two addresses", address: "user@example.com, other@example.com", wantErr: ErrInvalidAddress},
+		{name: "Line break", address: "user@example.com\r\nBcc: x@example.com", wantErr: ErrInvalidAddress},
+		{name: "Too long", address: strings.Repeat("a", 250) + "@example.com", wantErr: ErrInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeAddress(tt.address)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NormalizeAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrNotFound -
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey -
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore stores uploaded files by key. Keys are generated by the server
+// and are made of letters, digits, dots, dashes and underscores.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var keyRegex = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// FileStore is a BlobStore that keeps every blob as a file in one directory.
+type FileStore struct {
+	Root string
+}
+
+// NewFileStore creates the root directory if it doesn't exist yet.
+func NewFileStore(root string) (*FileStore, error) {
+	err := os.MkdirAll(root, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{Root: root}, nil
+}
+
+// Put writes to a temporary file first so readers never see a partial blob.
+func (s *FileStore) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.Root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open -
+func (s *FileStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete -
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	if !keyRegex.MatchString(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.Root, key), nil
+}
@@ -0,0 +1,103 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// MaxDimension bounds the width and height of uploaded images so a small
+	// file can't decode into an enormous bitmap.
+	MaxDimension = 8192
+	// MaxPixels bounds the area of uploaded images too, since an image at
+	// MaxDimension on both sides would still take 256 MiB to decode.
+	MaxPixels = 4096 * 4096
+	// ThumbnailSize is the longest side of a thumbnail in pixels.
+	ThumbnailSize = 320
+)
+
+// ErrUnsupportedType -
+var ErrUnsupportedType = errors.New("unsupported media type")
+
+// ErrTooLarge -
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// Info describes an uploaded image.
+type Info struct {
+	ContentType string
+	Width       int
+	Height      int
+}
+
+var decoders = map[string]func([]byte) (image.Image, error){
+	"image/png": func(data []byte) (image.Image, error) {
+		return png.Decode(bytes.NewReader(data))
+	},
+	"image/jpeg": func(data []byte) (image.Image, error) {
+		return jpeg.Decode(bytes.NewReader(data))
+	},
+	"image/gif": func(data []byte) (image.Image, error) {
+		return gif.Decode(bytes.NewReader(data))
+	},
+}
+
+// Inspect sniffs the content type of an upload from its bytes, ignoring
+// whatever the client claimed, and reads the image dimensions.
+func Inspect(data []byte) (Info, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := decoders[contentType]; !ok {
+		return Info{}, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Info{}, err
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension || config.Width*config.Height > MaxPixels {
+		return Info{}, ErrTooLarge
+	}
+
+	return Info{
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
+
+// Thumbnail scales an image inspected by Inspect down so that its longest
+// side is at most ThumbnailSize. JPEGs stay JPEGs; everything else becomes a
+// PNG to keep transparency. It returns the encoded thumbnail and its type.
+func Thumbnail(data []byte, info Info) ([]byte, string, error) {
+	src, err := decoders[info.ContentType](data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	width, height := info.Width, info.Height
+	if width > ThumbnailSize || height > ThumbnailSize {
+		if width >= height {
+			height = max(1, height*ThumbnailSize/width)
+			width = ThumbnailSize
+		} else {
+			width = max(1, width*ThumbnailSize/height)
+			height = ThumbnailSize
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+	if info.ContentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err = png.Encode(&buf, dst)
+	return buf.Bytes(), "image/png", err
+}
@@ -0,0 +1,200 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func encodeTestImage(t *testing.T, width, height int, encode func(io.Writer, image.Image) error) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	err := encode(&buf, img)
+	if err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader is the start of a PNG of the given size, just enough for
+// image.DecodeConfig, so tests don't have to encode huge images.
+func pngHeader(width, height int) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(width))
+	binary.BigEndian.PutUint32(ihdr[8:], uint32(height))
+	ihdr[12] = 8 // bit depth
+	ihdr[13] = 6 // RGBA
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, 13)
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func encodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, nil)
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantInfo Info
+		wantErr  error
+	}{
+		{
+			name:     "png",
+			data:     encodeTestImage(t, 640, 480, png.Encode),
+			wantInfo: Info{ContentType: "image/png", Width: 640, Height: 480},
+		},
+		{
+			name:     "jpeg",
+			data:     encodeTestImage(t, 10, 20, encodeJPEG),
+			wantInfo: Info{ContentType: "image/jpeg", Width: 10, Height: 20},
+		},
+		{
+			name:     "long and narrow",
+			data:     pngHeader(MaxDimension, 100),
+			wantInfo: Info{ContentType: "image/png", Width: MaxDimension, Height: 100},
+		},
+		{
+			name:    "too wide",
+			data:    pngHeader(MaxDimension+1, 100),
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "too many pixels",
+			data:    pngHeader(MaxDimension, MaxDimension/2),
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "text",
+			data:    []byte("definitely not an image"),
+			wantErr: ErrUnsupportedType,
+		},
+		{
+			name:    "html",
+			data:    []byte("<html><script>alert(1)</script></html>"),
+			wantErr: ErrUnsupportedType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Inspect(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Inspect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if info != tt.wantInfo {
+				t.Errorf("Inspect() = %+v, want %+v", info, tt.wantInfo)
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		wantType   string
+		wantWidth  int
+		wantHeight int
+	}{
+		{
+			name:       "wide png",
+			data:       encodeTestImage(t, 1000, 500, png.Encode),
+			wantType:   "image/png",
+			wantWidth:  ThumbnailSize,
+			wantHeight: ThumbnailSize / 2,
+		},
+		{
+			name:       "tall jpeg",
+			data:       encodeTestImage(t, 400, 800, encodeJPEG),
+			wantType:   "image/jpeg",
+			wantWidth:  ThumbnailSize / 2,
+			wantHeight: ThumbnailSize,
+		},
+		{
+			name:       "small image keeps its size",
+			data:       encodeTestImage(t, 50, 40, png.Encode),
+			wantType:   "image/png",
+			wantWidth:  50,
+			wantHeight: 40,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Inspect(tt.data)
+			if err != nil {
+				t.Fatalf("Inspect() error = %v", err)
+			}
+			thumb, contentType, err := Thumbnail(tt.data, info)
+			if err != nil {
+				t.Fatalf("Thumbnail() error = %v", err)
+			}
+			if contentType != tt.wantType {
+				t.Errorf("Thumbnail() type = %q, want %q", contentType, tt.wantType)
+			}
+			thumbInfo, err := Inspect(thumb)
+			if err != nil {
+				t.Fatalf("Inspect() on thumbnail error = %v", err)
+			}
+			if thumbInfo.Width != tt.wantWidth || thumbInfo.Height != tt.wantHeight {
+				t.Errorf("Thumbnail() is %dx%d, want %dx%d", thumbInfo.Width, thumbInfo.Height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	err = store.Put(ctx, "abc.png", bytes.NewReader([]byte("hello")))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	rc, err := store.Open(ctx, "abc.png")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(got) != "hello" {
+		t.Errorf("Open() read %q, want %q", got, "hello")
+	}
+
+	err = store.Delete(ctx, "abc.png")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	_, err = store.Open(ctx, "abc.png")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() after Delete() error = %v, want %v", err, ErrNotFound)
+	}
+
+	for _, key := range []string{"", "../etc/passwd", "a/b", ".hidden", ".."} {
+		err = store.Put(ctx, key, bytes.NewReader(nil))
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want %v", key, err, ErrInvalidKey)
+		}
+	}
+}
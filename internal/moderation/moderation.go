@@ -0,0 +1,163 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action is what happens to a chirp when a rule matches it.
+type Action string
+
+const (
+	// ActionMask replaces the matched text with asterisks
+	ActionMask Action = "mask"
+	// ActionReject refuses the chirp
+	ActionReject Action = "reject"
+	// ActionFlag lets the chirp through but records it for review
+	ActionFlag Action = "flag"
+)
+
+// ParseAction -
+func ParseAction(s string) (Action, error) {
+	switch Action(s) {
+	case ActionMask, ActionReject, ActionFlag:
+		return Action(s), nil
+	}
+	return "", fmt.Errorf("unknown moderation action %q", s)
+}
+
+// MaskText replaces masked spans of a chirp
+const MaskText = "****"
+
+// Match is a span of a chirp body that a rule matched. Start and End are
+// byte offsets into the body.
+type Match struct {
+	Rule   string
+	Action Action
+	Start  int
+	End    int
+}
+
+// Stage finds rule matches in a chirp. Stages only report matches; the
+// Pipeline decides what to do with them.
+type Stage interface {
+	Check(ctx context.Context, body string, tokens []Token) ([]Match, error)
+}
+
+// Result is the outcome of moderating a chirp.
+type Result struct {
+	// Body is the chirp with every masked span replaced by MaskText
+	Body string
+	// Masked holds the byte ranges of Body that are MaskText
+	Masked [][2]int
+	// RejectedBy names the first rejecting rule, if any
+	RejectedBy string
+	// FlaggedBy names every rule that flagged the chirp for review
+	FlaggedBy []string
+}
+
+// Rejected -
+func (r Result) Rejected() bool {
+	return r.RejectedBy != ""
+}
+
+// Moderator decides whether and how a chirp may be published.
+type Moderator interface {
+	Moderate(ctx context.Context, body string) (Result, error)
+}
+
+// Pipeline is a Moderator that runs every stage over the original body and
+// then applies the strongest action found.
+type Pipeline struct {
+	Stages []Stage
+}
+
+// NewPipeline -
+func NewPipeline(stages ...Stage) *Pipeline {
+	return &Pipeline{Stages: stages}
+}
+
+// Moderate -
+func (p *Pipeline) Moderate(ctx context.Context, body string) (Result, error) {
+	tokens := Tokenize(body)
+	matches := []Match{}
+	for _, stage := range p.Stages {
+		stageMatches, err := stage.Check(ctx, body, tokens)
+		if err != nil {
+			return Result{}, err
+		}
+		matches = append(matches, stageMatches...)
+	}
+
+	result := Result{Body: body}
+	masks := []Match{}
+	flagged := map[string]struct{}{}
+	for _, match := range matches {
+		switch match.Action {
+		case ActionReject:
+			if result.RejectedBy == "" {
+				result.RejectedBy = match.Rule
+			}
+		case ActionFlag:
+			if _, ok := flagged[match.Rule]; !ok {
+				flagged[match.Rule] = struct{}{}
+				result.FlaggedBy = append(result.FlaggedBy, match.Rule)
+			}
+		case ActionMask:
+			masks = append(masks, match)
+		}
+	}
+	if result.Rejected() {
+		return result, nil
+	}
+
+	result.Body, result.Masked = applyMasks(body, masks)
+	return result, nil
+}
+
+// Reloader is implemented by stages whose rules can change at runtime.
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// Reload reloads every stage of the pipeline that supports it.
+func (p *Pipeline) Reload(ctx context.Context) error {
+	errs := []error{}
+	for _, stage := range p.Stages {
+		if reloader, ok := stage.(Reloader); ok {
+			errs = append(errs, reloader.Reload(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyMasks replaces each masked span, merging spans that overlap. It also
+// returns where the masks ended up in the new body.
+func applyMasks(body string, masks []Match) (string, [][2]int) {
+	if len(masks) == 0 {
+		return body, nil
+	}
+	sort.Slice(masks, func(i, j int) bool {
+		return masks[i].Start < masks[j].Start
+	})
+
+	var b strings.Builder
+	masked := [][2]int{}
+	last := 0
+	for _, mask := range masks {
+		if mask.Start < last {
+			// Overlaps the previous mask, so grow that one instead.
+			last = max(last, mask.End)
+			continue
+		}
+		b.WriteString(body[last:mask.Start])
+		masked = append(masked, [2]int{b.Len(), b.Len() + len(MaskText)})
+		b.WriteString(MaskText)
+		last = mask.End
+	}
+	b.WriteString(body[last:])
+	return b.String(), masked
+}
@@ -0,0 +1,298 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type staticSource []Rule
+
+func (s staticSource) LoadRules(ctx context.Context) ([]Rule, error) {
+	return s, nil
+}
+
+type failingSource struct {
+	rules []Rule
+	fail  bool
+}
+
+func (s *failingSource) LoadRules(ctx context.Context) ([]Rule, error) {
+	if s.fail {
+		return nil, errors.New("source unavailable")
+	}
+	return s.rules, nil
+}
+
+func newTestPipeline(t *testing.T, rules ...Rule) *Pipeline {
+	t.Helper()
+	stage, err := NewRuleStage(context.Background(), staticSource(rules))
+	if err != nil {
+		t.Fatalf("NewRuleStage() error = %v", err)
+	}
+	return NewPipeline(stage)
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "spaces",
+			body: "hello world",
+			want: []string{"hello", "world"},
+		},
+		{
+			name: "punctuation",
+			body: "Kerfuffle! fornax, (sharbert)",
+			want: []string{"kerfuffle", "fornax", "sharbert"},
+		},
+		{
+			name: "full-width letters",
+			body: "ｆｏｒｎａｘ",
+			want: []string{"fornax"},
+		},
+		{
+			name: "non-latin scripts",
+			body: "Привет, мир",
+			want: []string{"привет", "мир"},
+		},
+		{
+			name: "empty",
+			body: "  !! ",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, token := range Tokenize(tt.body) {
+				if tt.body[token.Start:token.End] != token.Text {
+					t.Errorf("Tokenize() token %q has offsets [%d, %d)", token.Text, token.Start, token.End)
+				}
+				got = append(got, token.Normalized)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipelineModerate(t *testing.T) {
+	pipeline := newTestPipeline(t,
+		Rule{Kind: RuleKindWord, Pattern: "kerfuffle", Action: ActionMask},
+		Rule{Kind: RuleKindWord, Pattern: "fornax", Action: ActionMask},
+		Rule{Kind: RuleKindWord, Pattern: "spam", Action: ActionReject},
+		Rule{Kind: RuleKindWord, Pattern: "crypto", Action: ActionFlag},
+		Rule{Kind: RuleKindRegex, Pattern: `(?i)buy\s+followers`, Action: ActionReject},
+		Rule{Kind: RuleKindRegex, Pattern: `\d{3}-\d{4}`, Action: ActionMask},
+	)
+
+	tests := []struct {
+		name         string
+		body         string
+		wantBody     string
+		wantRejected string
+		wantFlagged  []string
+	}{
+		{
+			name:     "clean",
+			body:     "I had something interesting for breakfast",
+			wantBody: "I had something interesting for breakfast",
+		},
+		{
+			name:     "masks words next to punctuation",
+			body:     "This is a Kerfuffle! opinion, fornax.",
+			wantBody: "This is a ****! opinion, ****.",
+		},
+		{
+			name:     "leaves words containing a bad word",
+			body:     "kerfuffles are fine",
+			wantBody: "kerfuffles are fine",
+		},
+		{
+			name:         "rejects",
+			body:         "this is SPAM",
+			wantBody:     "this is SPAM",
+			wantRejected: "word:spam",
+		},
+		{
+			name:         "rejects by regex",
+			body:         "Buy   followers now",
+			wantBody:     "Buy   followers now",
+			wantRejected: `regex:(?i)buy\s+followers`,
+		},
+		{
+			name:        "flags without changing the body",
+			body:        "crypto is great, crypto!",
+			wantBody:    "crypto is great, crypto!",
+			wantFlagged: []string{"word:crypto"},
+		},
+		{
+			name:     "masks by regex",
+			body:     "call 555-1234 fornax",
+			wantBody: "call **** ****",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := pipeline.Moderate(context.Background(), tt.body)
+			if err != nil {
+				t.Fatalf("Moderate() error = %v", err)
+			}
+			if result.Body != tt.wantBody {
+				t.Errorf("Moderate() body = %q, want %q", result.Body, tt.wantBody)
+			}
+			if result.RejectedBy != tt.wantRejected {
+				t.Errorf("Moderate() rejected by = %q, want %q", result.RejectedBy, tt.wantRejected)
+			}
+			if !reflect.DeepEqual(result.FlaggedBy, tt.wantFlagged) {
+				t.Errorf("Moderate() flagged by = %v, want %v", result.FlaggedBy, tt.wantFlagged)
+			}
+		})
+	}
+}
+
+func TestApplyMasksOverlapping(t *testing.T) {
+	got, masked := applyMasks("abcdefgh", []Match{
+		{Start: 4, End: 6},
+		{Start: 1, End: 5},
+	})
+	if got != "a****gh" {
+		t.Errorf("applyMasks() = %q, want %q", got, "a****gh")
+	}
+	if want := [][2]int{{1, 5}}; !reflect.DeepEqual(masked, want) {
+		t.Errorf("applyMasks() masked = %v, want %v", masked, want)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	input := `# words
+mask kerfuffle
+
+reject re:(?i)buy\s+followers
+flag   crypto
+`
+	rules, err := ParseRules(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	want := []Rule{
+		{Kind: RuleKindWord, Pattern: "kerfuffle", Action: ActionMask},
+		{Kind: RuleKindRegex, Pattern: `(?i)buy\s+followers`, Action: ActionReject},
+		{Kind: RuleKindWord, Pattern: "crypto", Action: ActionFlag},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseRules() = %v, want %v", rules, want)
+	}
+
+	_, err = ParseRules(strings.NewReader("ban kerfuffle"))
+	if err == nil {
+		t.Error("ParseRules() should fail on an unknown action")
+	}
+}
+
+func TestRuleStageReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.txt")
+	err := os.WriteFile(path, []byte("mask kerfuffle\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stage, err := NewRuleStage(context.Background(), FileSource{Path: path})
+	if err != nil {
+		t.Fatalf("NewRuleStage() error = %v", err)
+	}
+	pipeline := NewPipeline(stage)
+
+	result, _ := pipeline.Moderate(context.Background(), "kerfuffle sharbert")
+	if result.Body != "**** sharbert" {
+		t.Errorf("Moderate() before reload = %q", result.Body)
+	}
+
+	err = os.WriteFile(path, []byte("mask sharbert\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pipeline.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	result, _ = pipeline.Moderate(context.Background(), "kerfuffle sharbert")
+	if result.Body != "kerfuffle ****" {
+		t.Errorf("Moderate() after reload = %q", result.Body)
+	}
+}
+
+func TestRuleStageIgnoresEmptyMatches(t *testing.T) {
+	stage, err := NewRuleStage(context.Background(), staticSource{
+		{Kind: RuleKindRegex, Pattern: `x*`, Action: ActionMask},
+		{Kind: RuleKindRegex, Pattern: `\b`, Action: ActionMask},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleStage() error = %v", err)
+	}
+
+	result, err := NewPipeline(stage).Moderate(context.Background(), "a xx b")
+	if err != nil {
+		t.Fatalf("Moderate() error = %v", err)
+	}
+	if result.Body != "a **** b" {
+		t.Errorf("Moderate() body = %q, want %q", result.Body, "a **** b")
+	}
+	if want := [][2]int{{2, 6}}; !reflect.DeepEqual(result.Masked, want) {
+		t.Errorf("Moderate() masked = %v, want %v", result.Masked, want)
+	}
+}
+
+func TestRuleStageReloadKeepsRulesOnError(t *testing.T) {
+	source := &failingSource{
+		rules: []Rule{{Kind: RuleKindWord, Pattern: "fornax", Action: ActionMask}},
+	}
+	stage, err := NewRuleStage(context.Background(), source)
+	if err != nil {
+		t.Fatalf("NewRuleStage() error = %v", err)
+	}
+
+	source.fail = true
+	err = stage.Reload(context.Background())
+	if err == nil {
+		t.Fatal("Reload() should fail when the source fails")
+	}
+
+	result, _ := NewPipeline(stage).Moderate(context.Background(), "fornax")
+	if result.Body != "****" {
+		t.Errorf("Moderate() after failed reload = %q, want %q", result.Body, "****")
+	}
+}
+
+func TestNewRuleStageErrors(t *testing.T) {
+	_, err := NewRuleStage(context.Background(), &failingSource{fail: true})
+	if err == nil {
+		t.Error("NewRuleStage() should fail when the source fails")
+	}
+
+	_, err = NewRuleStage(context.Background(), staticSource{
+		{Kind: RuleKindWord, Pattern: "two words", Action: ActionMask},
+	})
+	if err == nil {
+		t.Error("NewRuleStage() should reject a word rule with spaces")
+	}
+
+	_, err = NewRuleStage(context.Background(), staticSource{
+		{Kind: RuleKindRegex, Pattern: "(", Action: ActionMask},
+	})
+	if err == nil {
+		t.Error("NewRuleStage() should reject an invalid regex")
+	}
+}
@@ -0,0 +1,196 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// RuleKind -
+type RuleKind string
+
+const (
+	// RuleKindWord matches a whole word after normalization
+	RuleKindWord RuleKind = "word"
+	// RuleKindRegex matches a regular expression anywhere in the body
+	RuleKindRegex RuleKind = "regex"
+)
+
+// Rule is a single entry in a word list or rule file.
+type Rule struct {
+	Kind    RuleKind
+	Pattern string
+	Action  Action
+}
+
+// Name identifies the rule in rejection messages and review flags.
+func (r Rule) Name() string {
+	return string(r.Kind) + ":" + r.Pattern
+}
+
+// RuleSource supplies the rules for a RuleStage. It is consulted again every
+// time the stage is reloaded.
+type RuleSource interface {
+	LoadRules(ctx context.Context) ([]Rule, error)
+}
+
+// FileSource reads rules from a text file with one rule per line:
+//
+//	# comment
+//	mask kerfuffle
+//	reject re:(?i)buy\s+followers
+//
+// The first field is the action. The rest of the line is a word, or a
+// regular expression when it starts with "re:".
+type FileSource struct {
+	Path string
+}
+
+// LoadRules -
+func (s FileSource) LoadRules(ctx context.Context) ([]Rule, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseRules(f)
+}
+
+// ParseRules reads rules in the FileSource format.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	rules := []Rule{}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		actionString, pattern, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected an action and a pattern", lineNumber)
+		}
+		action, err := ParseAction(actionString)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		pattern = strings.TrimSpace(pattern)
+
+		rule := Rule{Kind: RuleKindWord, Pattern: pattern, Action: action}
+		if regex, ok := strings.CutPrefix(pattern, "re:"); ok {
+			rule = Rule{Kind: RuleKindRegex, Pattern: regex, Action: action}
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// RuleStage is a Stage that checks word and regex rules from a RuleSource.
+// Reload swaps in a fresh copy of the rules without blocking Check.
+type RuleStage struct {
+	source RuleSource
+	rules  atomic.Pointer[compiledRules]
+}
+
+type compiledRules struct {
+	words   map[string][]Rule
+	regexes []compiledRegex
+}
+
+type compiledRegex struct {
+	rule  Rule
+	regex *regexp.Regexp
+}
+
+// NewRuleStage loads the rules from source once before returning.
+func NewRuleStage(ctx context.Context, source RuleSource) (*RuleStage, error) {
+	stage := &RuleStage{source: source}
+	err := stage.Reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stage, nil
+}
+
+// Reload fetches the rules from the source again. On error the previous rules
+// stay in effect.
+func (s *RuleStage) Reload(ctx context.Context) error {
+	rules, err := s.source.LoadRules(ctx)
+	if err != nil {
+		return err
+	}
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return err
+	}
+	s.rules.Store(compiled)
+	return nil
+}
+
+// Check -
+func (s *RuleStage) Check(ctx context.Context, body string, tokens []Token) ([]Match, error) {
+	rules := s.rules.Load()
+	matches := []Match{}
+	for _, token := range tokens {
+		for _, rule := range rules.words[token.Normalized] {
+			matches = append(matches, Match{
+				Rule:   rule.Name(),
+				Action: rule.Action,
+				Start:  token.Start,
+				End:    token.End,
+			})
+		}
+	}
+	for _, compiled := range rules.regexes {
+		for _, loc := range compiled.regex.FindAllStringIndex(body, -1) {
+			// Patterns such as `x*` or `\b` also match nothing at all, which
+			// would mask every chirp.
+			if loc[0] == loc[1] {
+				continue
+			}
+			matches = append(matches, Match{
+				Rule:   compiled.rule.Name(),
+				Action: compiled.rule.Action,
+				Start:  loc[0],
+				End:    loc[1],
+			})
+		}
+	}
+	return matches, nil
+}
+
+func compileRules(rules []Rule) (*compiledRules, error) {
+	compiled := &compiledRules{
+		words: map[string][]Rule{},
+	}
+	for _, rule := range rules {
+		switch rule.Kind {
+		case RuleKindWord:
+			word := Normalize(rule.Pattern)
+			if !IsWord(word) {
+				return nil, fmt.Errorf("word rule %q must be a single word", rule.Pattern)
+			}
+			compiled.words[word] = append(compiled.words[word], rule)
+		case RuleKindRegex:
+			regex, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("regex rule %q: %w", rule.Pattern, err)
+			}
+			compiled.regexes = append(compiled.regexes, compiledRegex{rule: rule, regex: regex})
+		default:
+			return nil, errors.New("unknown rule kind " + string(rule.Kind))
+		}
+	}
+	return compiled, nil
+}
@@ -0,0 +1,76 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Token is a word in a chirp. Start and End are byte offsets into the body
+// and Normalized is the form rules are compared against.
+type Token struct {
+	Text       string
+	Normalized string
+	Start      int
+	End        int
+}
+
+// Tokenize splits a chirp into words. A word is a run of letters, digits
+// and combining marks in any script, so punctuation such as "Kerfuffle!" or
+// "fornax," never hides a word from the word lists.
+func Tokenize(body string) []Token {
+	tokens := []Token{}
+	start := -1
+	for i, r := range body {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, newToken(body, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(body, start, len(body)))
+	}
+	return tokens
+}
+
+// Normalize folds a word so that visually equivalent spellings compare
+// equal: compatibility forms such as full-width letters are decomposed and
+// case is folded.
+func Normalize(word string) string {
+	return strings.ToLower(norm.NFKC.String(word))
+}
+
+// IsWord reports whether s is exactly one token, i.e. something a word list
+// entry can match.
+func IsWord(s string) bool {
+	if s == "" || !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+func newToken(body string, start, end int) Token {
+	return Token{
+		Text:       body[start:end],
+		Normalized: Normalize(body[start:end]),
+		Start:      start,
+		End:        end,
+	}
+}
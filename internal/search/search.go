@@ -0,0 +1,70 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrEmptyQuery -
+var ErrEmptyQuery = errors.New("search query has no searchable terms")
+
+// ToTSQuery turns a user supplied search string into a Postgres tsquery
+// expression suitable for to_tsquery. Double quoted text becomes a phrase
+// match, a trailing * makes a prefix match, and all terms must match.
+// Anything that isn't a letter or digit is dropped so user input can never
+// inject tsquery operators.
+func ToTSQuery(q string) (string, error) {
+	terms := []string{}
+	for i, part := range strings.Split(q, `"`) {
+		// Odd parts sit between a pair of quotes.
+		if i%2 == 1 {
+			if phrase := phraseTerm(part); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			if term := wordTerm(field); term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+	if len(terms) == 0 {
+		return "", ErrEmptyQuery
+	}
+	return strings.Join(terms, " & "), nil
+}
+
+func phraseTerm(s string) string {
+	words := lexemes(s)
+	if len(words) == 0 {
+		return ""
+	}
+	if len(words) == 1 {
+		return words[0]
+	}
+	return "(" + strings.Join(words, " <-> ") + ")"
+}
+
+func wordTerm(s string) string {
+	prefix := strings.HasSuffix(s, "*")
+	words := lexemes(s)
+	if len(words) == 0 {
+		return ""
+	}
+	if prefix {
+		words[len(words)-1] += ":*"
+	}
+	if len(words) == 1 {
+		return words[0]
+	}
+	// Words like "e-mail" are split into pieces that must appear together.
+	return "(" + strings.Join(words, " <-> ") + ")"
+}
+
+func lexemes(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestToTSQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "single word",
+			query: "Hello",
+			want:  "hello",
+		},
+		{
+			name:  "several words",
+			query: "hello   world",
+			want:  "hello & world",
+		},
+		{
+			name:  "phrase",
+			query: `"good morning" world`,
+			want:  "(good <-> morning) & world",
+		},
+		{
+			name:  "prefix",
+			query: "chir*",
+			want:  "chir:*",
+		},
+		{
+			name:  "hyphenated word",
+			query: "e-mail",
+			want:  "(e <-> mail)",
+		},
+		{
+			name:  "unterminated quote",
+			query: `"good morning`,
+			want:  "(good <-> morning)",
+		},
+		{
+			name:  "operators are stripped",
+			query: "a&b | !c:*",
+			want:  "(a <-> b) & c:*",
+		},
+		{
+			name:  "unicode",
+			query: "καλημέρα",
+			want:  "καλημέρα",
+		},
+		{
+			name:    "empty",
+			query:   "  ",
+			wantErr: ErrEmptyQuery,
+		},
+		{
+			name:    "only punctuation",
+			query:   `"" !!`,
+			wantErr: ErrEmptyQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToTSQuery(tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ToTSQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToTSQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
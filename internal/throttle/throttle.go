@@ -0,0 +1,166 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// State is what a Store knows about a key's recent failures.
+type State struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Store counts failures per key, e.g. per account or per client IP.
+type Store interface {
+	// Get returns the state of key, the zero State if it has no failures.
+	Get(ctx context.Context, key string) (State, error)
+	// Attempt counts an attempt of key at now as a failure and returns the
+	// state from just before it, which the attempt should be judged by.
+	// Counting first means parallel attempts each see the ones before them.
+	// Failures from before since are forgotten, so the count starts over.
+	Attempt(ctx context.Context, key string, since, now time.Time) (State, error)
+	// Refund takes back an attempt made at now that turned out not to be a
+	// failure. previous is what Attempt returned for it.
+	Refund(ctx context.Context, key string, now time.Time, previous State) error
+	// Reset forgets the failures of key.
+	Reset(ctx context.Context, key string) error
+	// Prune forgets every key whose last failure was before since.
+	Prune(ctx context.Context, since time.Time) error
+}
+
+// Policy decides how long a key has to wait after failing.
+type Policy struct {
+	// FreeFailures is how many failures are allowed before backing off.
+	FreeFailures int
+	// BaseDelay is the wait after the first failure beyond FreeFailures. It
+	// doubles with every further failure, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutFailures is the number of failures that locks the key out for
+	// LockoutDuration after the last one. Zero disables lockouts.
+	LockoutFailures int
+	LockoutDuration time.Duration
+	// ResetAfter is how long after its last failure a key starts over. It
+	// should be at least MaxDelay and LockoutDuration.
+	ResetAfter time.Duration
+}
+
+// Decision is what a Policy says about a key.
+type Decision struct {
+	// Wait is how long the key has to wait before trying again; zero means
+	// it can try now.
+	Wait time.Duration
+	// Locked is set when the wait is a lockout rather than a backoff.
+	Locked bool
+}
+
+// Allowed -
+func (d Decision) Allowed() bool {
+	return d.Wait <= 0
+}
+
+// Decide applies the policy to a key's state at now.
+func (p Policy) Decide(s State, now time.Time) Decision {
+	if s.Failures == 0 || now.Sub(s.LastFailure) >= p.ResetAfter {
+		return Decision{}
+	}
+
+	if p.LockoutFailures > 0 && s.Failures >= p.LockoutFailures {
+		wait := s.LastFailure.Add(p.LockoutDuration).Sub(now)
+		if wait > 0 {
+			return Decision{Wait: wait, Locked: true}
+		}
+		return Decision{}
+	}
+
+	excess := s.Failures - p.FreeFailures
+	if excess <= 0 {
+		return Decision{}
+	}
+	delay := p.BaseDelay
+	for i := 1; i < excess && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxDelay)
+	wait := s.LastFailure.Add(delay).Sub(now)
+	if wait > 0 {
+		return Decision{Wait: wait}
+	}
+	return Decision{}
+}
+
+// Since is the time before which failures no longer count at now, to pass
+// to Store.Attempt.
+func (p Policy) Since(now time.Time) time.Time {
+	return now.Add(-p.ResetAfter)
+}
+
+// MemoryStore is a Store for a single server. Its counts are lost on
+// restart.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+// NewMemoryStore -
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string]State{}}
+}
+
+// Get -
+func (s *MemoryStore) Get(ctx context.Context, key string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[key], nil
+}
+
+// Attempt -
+func (s *MemoryStore) Attempt(ctx context.Context, key string, since, now time.Time) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.states[key]
+	if previous.LastFailure.Before(since) {
+		previous.Failures = 0
+	}
+	s.states[key] = State{Failures: previous.Failures + 1, LastFailure: now}
+	return previous, nil
+}
+
+// Refund -
+func (s *MemoryStore) Refund(ctx context.Context, key string, now time.Time, previous State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[key]
+	if !ok {
+		return nil
+	}
+	state.Failures = max(state.Failures-1, 0)
+	// Later attempts keep their time; this one never happened.
+	if state.LastFailure.Equal(now) {
+		state.LastFailure = previous.LastFailure
+	}
+	s.states[key] = state
+	return nil
+}
+
+// Reset -
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key)
+	return nil
+}
+
+// Prune -
+func (s *MemoryStore) Prune(ctx context.Context, since time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, state := range s.states {
+		if state.LastFailure.Before(since) {
+			delete(s.states, key)
+		}
+	}
+	return nil
+}
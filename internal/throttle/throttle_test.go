@@ -0,0 +1,202 @@
+package throttle
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPolicyDecide(t *testing.T) {
+	policy := Policy{
+		FreeFailures:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        10 * time.Second,
+		LockoutFailures: 10,
+		LockoutDuration: 15 * time.Minute,
+		ResetAfter:      time.Hour,
+	}
+	last := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		state State
+		now   time.Time
+		want  Decision
+	}{
+		{
+			name: "No failures",
+			now:  last,
+			want: Decision{},
+		},
+		{
+			name:  "Free failures",
+			state: State{Failures: 3, LastFailure: last},
+			now:   last,
+			want:  Decision{},
+		},
+		{
+			name:  "First backoff",
+			state: State{Failures: 4, LastFailure: last},
+			now:   last,
+			want:  Decision{Wait: time.Second},
+		},
+		{
+			name:  "Backoff doubles",
+			state: State{Failures: 6, LastFailure: last},
+			now:   last.Add(time.Second),
+			want:  Decision{Wait: 3 * time.Second},
+		},
+		{
+			name:  "Backoff is capped",
+			state: State{Failures: 9, LastFailure: last},
+			now:   last,
+			want:  Decision{Wait: 10 * time.Second},
+		},
+		{
+			name:  "Backoff over",
+			state: State{Failures: 9, LastFailure: last},
+			now:   last.Add(10 * time.Second),
+			want:  Decision{},
+		},
+		{
+			name:  "Locked out",
+			state: State{Failures: 10, LastFailure: last},
+			now:   last.Add(5 * time.Minute),
+			want:  Decision{Wait: 10 * time.Minute, Locked: true},
+		},
+		{
+			name:  "Lockout over",
+			state: State{Failures: 12, LastFailure: last},
+			now:   last.Add(15 * time.Minute),
+			want:  Decision{},
+		},
+		{
+			name:  "Old failures are forgotten",
+			state: State{Failures: 5, LastFailure: last},
+			now:   last.Add(time.Hour),
+			want:  Decision{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Decide(tt.state, tt.now)
+			if got != tt.want {
+				t.Errorf("Decide() = %+v, want %+v", got, tt.want)
+			}
+			if got.Allowed() != (tt.want.Wait == 0) {
+				t.Errorf("Allowed() = %v with wait %s", got.Allowed(), got.Wait)
+			}
+		})
+	}
+}
+
+func TestPolicyWithoutLockout(t *testing.T) {
+	policy := Policy{BaseDelay: time.Second, MaxDelay: time.Minute, ResetAfter: time.Hour}
+	last := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	got := policy.Decide(State{Failures: 100, LastFailure: last}, last)
+	if got != (Decision{Wait: time.Minute}) {
+		t.Errorf("Decide() = %+v, want a capped backoff", got)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	state, err := store.Get(ctx, "a")
+	if err != nil || state != (State{}) {
+		t.Fatalf("Get() = %+v, %v, want no failures", state, err)
+	}
+
+	for i := range 3 {
+		state, err = store.Attempt(ctx, "a", start.Add(-time.Hour), start.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("Attempt() error = %v", err)
+		}
+	}
+	if state.Failures != 2 || !state.LastFailure.Equal(start.Add(time.Second)) {
+		t.Errorf("Attempt() = %+v, want the 2 failures before it", state)
+	}
+	want := State{Failures: 3, LastFailure: start.Add(2 * time.Second)}
+	if got, _ := store.Get(ctx, "a"); got != want {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+	if got, _ := store.Get(ctx, "b"); got != (State{}) {
+		t.Errorf("Get() of another key = %+v, want no failures", got)
+	}
+
+	// A refunded attempt leaves the state as it was before it.
+	refunded := start.Add(3 * time.Second)
+	previous, _ := store.Attempt(ctx, "a", start.Add(-time.Hour), refunded)
+	err = store.Refund(ctx, "a", refunded, previous)
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if got, _ := store.Get(ctx, "a"); got != want {
+		t.Errorf("Get() after Refund() = %+v, want %+v", got, want)
+	}
+
+	// An attempt after the reset window starts the count over.
+	later := start.Add(2 * time.Hour)
+	state, _ = store.Attempt(ctx, "a", later.Add(-time.Hour), later)
+	if state.Failures != 0 {
+		t.Errorf("Attempt() after the window = %+v, want no failures before it", state)
+	}
+
+	store.Attempt(ctx, "b", start.Add(-time.Hour), start)
+	err = store.Prune(ctx, later.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if got, _ := store.Get(ctx, "b"); got != (State{}) {
+		t.Errorf("Get() after Prune() = %+v, want no failures", got)
+	}
+	if got, _ := store.Get(ctx, "a"); got.Failures != 1 {
+		t.Errorf("Prune() removed a recent key: %+v", got)
+	}
+
+	err = store.Reset(ctx, "a")
+	if err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if got, _ := store.Get(ctx, "a"); got != (State{}) {
+		t.Errorf("Get() after Reset() = %+v, want no failures", got)
+	}
+}
+
+func TestMemoryStoreParallelAttempts(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := []int{}
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			previous, err := store.Attempt(ctx, "a", now.Add(-time.Hour), now)
+			if err != nil {
+				t.Errorf("Attempt() error = %v", err)
+				return
+			}
+			mu.Lock()
+			seen = append(seen, previous.Failures)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Each attempt sees every one that started before it.
+	slices.Sort(seen)
+	for i, failures := range seen {
+		if failures != i {
+			t.Fatalf("Attempt() saw failures %v, want 0 to 9 once each", seen)
+		}
+	}
+}
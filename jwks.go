@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gooneraki/chirpy-go/internal/auth"
+)
+
+// loadKeyring sets up access token signing from the environment. Tokens are
+// signed with the private key in JWT_SIGNING_KEY_FILE if it is set, and with
+// JWT_SECRET otherwise. JWT_VERIFICATION_KEY_FILES lists previous public
+// keys whose tokens are still accepted. When both a key file and JWT_SECRET
+// are set, HS256 tokens are still accepted too, so switching doesn't log
+// anyone out. Tokens are issued for and only accepted with the audience in
+// JWT_AUDIENCE, which defaults to auth.DefaultAudience.
+func loadKeyring() (*auth.Keyring, error) {
+	keyring, err := loadKeys()
+	if err != nil {
+		return nil, err
+	}
+	keyring.Audience = os.Getenv("JWT_AUDIENCE")
+	return keyring, nil
+}
+
+func loadKeys() (*auth.Keyring, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	signingKeyFile := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if signingKeyFile == "" {
+		if jwtSecret == "" {
+			return nil, errors.New("JWT_SECRET or JWT_SIGNING_KEY_FILE must be set")
+		}
+		return auth.NewHMACKeyring(jwtSecret), nil
+	}
+
+	signingKey, err := readKeyFile(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyingKeys := []*auth.Key{}
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		key, err := readKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verifyingKeys = append(verifyingKeys, key)
+	}
+	if jwtSecret != "" {
+		verifyingKeys = append(verifyingKeys, auth.NewHMACKey([]byte(jwtSecret)))
+	}
+
+	return auth.NewKeyring(signingKey, verifyingKeys...)
+}
+
+func readKeyFile(path string) (*auth.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := auth.ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+func (cfg *apiConfig) handlerJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, cfg.keyring.JWKS())
+}
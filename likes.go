@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+// setLikedByMe fills in LikedByMe on the given chirps for the viewer with a
+// single query. Anonymous viewers haven't liked anything.
+func (cfg *apiConfig) setLikedByMe(ctx context.Context, viewerID uuid.NullUUID, chirps ...*Chirp) error {
+	if !viewerID.Valid || len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+	likedIDs, err := cfg.db.GetLikedChirpIDs(ctx, database.GetLikedChirpIDsParams{
+		UserID:   viewerID.UUID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	liked := make(map[uuid.UUID]struct{}, len(likedIDs))
+	for _, id := range likedIDs {
+		liked[id] = struct{}{}
+	}
+	for _, chirp := range chirps {
+		_, chirp.LikedByMe = liked[chirp.ID]
+	}
+	return nil
+}
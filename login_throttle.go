@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/throttle"
+)
+
+// loginFailureResetAfter is how long after the last failed login an account
+// or IP starts over with a clean slate.
+const loginFailureResetAfter = time.Hour
+
+// loginThrottlePruneInterval is how often forgotten failures are removed
+// from the store.
+const loginThrottlePruneInterval = 10 * time.Minute
+
+var (
+	// accountLoginPolicy slows down password guessing against one account,
+	// from any number of IPs, and locks the account for a while after
+	// repeated failures.
+	accountLoginPolicy = throttle.Policy{
+		FreeFailures:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutFailures: 10,
+		LockoutDuration: 15 * time.Minute,
+		ResetAfter:      loginFailureResetAfter,
+	}
+	// ipLoginPolicy slows down one IP guessing across many accounts. It
+	// allows more failures, since many users can share an IP, and never
+	// locks out.
+	ipLoginPolicy = throttle.Policy{
+		FreeFailures: 20,
+		BaseDelay:    time.Second,
+		MaxDelay:     5 * time.Minute,
+		ResetAfter:   loginFailureResetAfter,
+	}
+)
+
+// dbLoginFailureStore keeps failed login counts in Postgres, so every
+// instance of the server sees them.
+type dbLoginFailureStore struct {
+	db *database.Queries
+}
+
+func (s dbLoginFailureStore) Get(ctx context.Context, key string) (throttle.State, error) {
+	failures, err := s.db.GetLoginFailures(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return throttle.State{}, nil
+	}
+	if err != nil {
+		return throttle.State{}, err
+	}
+	return throttle.State{Failures: int(failures.Failures), LastFailure: failures.LastFailureAt}, nil
+}
+
+func (s dbLoginFailureStore) Attempt(ctx context.Context, key string, since, now time.Time) (throttle.State, error) {
+	failures, err := s.db.AttemptLogin(ctx, database.AttemptLoginParams{
+		Key:   key,
+		Now:   now,
+		Since: since,
+	})
+	if err != nil {
+		return throttle.State{}, err
+	}
+	// The upsert counted this attempt and started over if the failures
+	// were old, so the state before it is one failure less.
+	return throttle.State{Failures: int(failures.Failures) - 1, LastFailure: failures.PreviousFailureAt.Time}, nil
+}
+
+func (s dbLoginFailureStore) Refund(ctx context.Context, key string, now time.Time, previous throttle.State) error {
+	return s.db.RefundLoginAttempt(ctx, database.RefundLoginAttemptParams{
+		Key:               key,
+		AttemptedAt:       now,
+		PreviousFailureAt: previous.LastFailure,
+	})
+}
+
+func (s dbLoginFailureStore) Reset(ctx context.Context, key string) error {
+	return s.db.ResetLoginFailures(ctx, key)
+}
+
+func (s dbLoginFailureStore) Prune(ctx context.Context, since time.Time) error {
+	_, err := s.db.PruneLoginFailures(ctx, since)
+	return err
+}
+
+func accountLoginKey(email string) string {
+	return "account:" + email
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginAttempt is a password or second factor check that startLoginAttempt
+// has counted as a failure, both for the account and the client IP, until
+// it is known to have succeeded.
+type loginAttempt struct {
+	email           string
+	ip              string
+	at              time.Time
+	previousAccount throttle.State
+	previousIP      throttle.State
+}
+
+// startLoginAttempt counts a login attempt for email before the password
+// or code is checked, so parallel guesses can't all get past the throttle.
+// It refuses the attempt while the account is locked out, with a 423, or
+// while the account or the client IP has to back off, with a 429. Both say
+// when to try again in Retry-After. It writes the error response itself and
+// reports whether the handler should continue, in which case the attempt
+// must end with failLoginAttempt or succeedLoginAttempt.
+func (cfg *apiConfig) startLoginAttempt(w http.ResponseWriter, r *http.Request, email string) (loginAttempt, bool) {
+	attempt := loginAttempt{
+		email: email,
+		ip:    clientIP(r),
+		at:    time.Now().UTC(),
+	}
+
+	var err error
+	attempt.previousIP, err = cfg.loginFailures.Attempt(r.Context(), ipLoginKey(attempt.ip), ipLoginPolicy.Since(attempt.at), attempt.at)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check login attempts", err)
+		return loginAttempt{}, false
+	}
+	attempt.previousAccount, err = cfg.loginFailures.Attempt(r.Context(), accountLoginKey(email), accountLoginPolicy.Since(attempt.at), attempt.at)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check login attempts", err)
+		return loginAttempt{}, false
+	}
+
+	decision := accountLoginPolicy.Decide(attempt.previousAccount, attempt.at)
+	ipDecision := ipLoginPolicy.Decide(attempt.previousIP, attempt.at)
+	if ipDecision.Wait > decision.Wait && !decision.Locked {
+		decision = ipDecision
+	}
+	if decision.Allowed() {
+		return attempt, true
+	}
+
+	// Turned away attempts aren't failures.
+	err = cfg.succeedLoginAttempt(r.Context(), attempt)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check login attempts", err)
+		return loginAttempt{}, false
+	}
+	setRetryAfter(w, decision.Wait)
+	if decision.Locked {
+		respondWithError(w, http.StatusLocked, "Account is temporarily locked after too many failed logins", nil)
+		return loginAttempt{}, false
+	}
+	respondWithError(w, http.StatusTooManyRequests, "Too many failed logins, try again later", nil)
+	return loginAttempt{}, false
+}
+
+// failLoginAttempt ends an attempt with a wrong password or second factor,
+// which startLoginAttempt already counted. userID is unset when nobody has
+// the email. Failures that lock the account are recorded as lockouts for
+// admins to review.
+func (cfg *apiConfig) failLoginAttempt(ctx context.Context, attempt loginAttempt, userID uuid.NullUUID) error {
+	accountState := throttle.State{
+		Failures:    attempt.previousAccount.Failures + 1,
+		LastFailure: attempt.at,
+	}
+	decision := accountLoginPolicy.Decide(accountState, attempt.at)
+	if !decision.Locked {
+		return nil
+	}
+	return cfg.db.CreateLoginLockout(ctx, database.CreateLoginLockoutParams{
+		Email:       attempt.email,
+		UserID:      userID,
+		IpAddress:   attempt.ip,
+		Failures:    int32(accountState.Failures),
+		LockedUntil: attempt.at.Add(decision.Wait),
+	})
+}
+
+// succeedLoginAttempt takes back the failure startLoginAttempt counted.
+func (cfg *apiConfig) succeedLoginAttempt(ctx context.Context, attempt loginAttempt) error {
+	err := cfg.loginFailures.Refund(ctx, ipLoginKey(attempt.ip), attempt.at, attempt.previousIP)
+	if err != nil {
+		return err
+	}
+	return cfg.loginFailures.Refund(ctx, accountLoginKey(attempt.email), attempt.at, attempt.previousAccount)
+}
+
+// resetLoginFailures clears an account's failures once its user has fully
+// logged in. The client IP's failures are left to expire, so an attacker
+// can't clear them by logging into an account of their own.
+func (cfg *apiConfig) resetLoginFailures(ctx context.Context, email string) error {
+	return cfg.loginFailures.Reset(ctx, accountLoginKey(email))
+}
+
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
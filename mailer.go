@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gooneraki/chirpy-go/internal/mail"
+)
+
+// loadMailer sets up sending emails from the environment. MAILER picks how:
+// "smtp" sends through SMTP_ADDR, logging in with SMTP_USERNAME and
+// SMTP_PASSWORD if set; "file" writes each email to MAIL_DIR; "log", the
+// default, only logs them. Emails come from MAIL_FROM.
+func loadMailer() (mail.Mailer, error) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "chirpy@localhost"
+	}
+
+	switch mailer := os.Getenv("MAILER"); mailer {
+	case "", "log":
+		return mail.LogMailer{}, nil
+	case "file":
+		mailDir := os.Getenv("MAIL_DIR")
+		if mailDir == "" {
+			mailDir = "mail"
+		}
+		return mail.NewFileMailer(mailDir, from)
+	case "smtp":
+		smtpAddr := os.Getenv("SMTP_ADDR")
+		if smtpAddr == "" {
+			return nil, errors.New("SMTP_ADDR must be set when MAILER is smtp")
+		}
+		return mail.NewSMTPMailer(smtpAddr, from, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+	default:
+		return nil, fmt.Errorf("invalid MAILER: %s", mailer)
+	}
+}
+
+// appLink is a link to a page of the web client at APP_URL, e.g. the one
+// that asks for a new password.
+func (cfg *apiConfig) appLink(path, token string) string {
+	return strings.TrimRight(cfg.appURL, "/") + path + "?token=" + token
+}
+
+// sendMail sends an email in the background, so how long the mail server
+// takes doesn't hold up the response or reveal whether a message was sent
+// at all.
+func (cfg *apiConfig) sendMail(ctx context.Context, msg mail.Message) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		err := cfg.mailer.Send(ctx, msg)
+		if err != nil {
+			log.Printf("Error sending email to %s: %s", msg.To, err)
+		}
+	}()
+}
@@ -1,23 +1,43 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/gooneraki/chirpy-go/internal/auth"
 	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/mail"
+	"github.com/gooneraki/chirpy-go/internal/media"
+	"github.com/gooneraki/chirpy-go/internal/moderation"
+	"github.com/gooneraki/chirpy-go/internal/throttle"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	db             *database.Queries
-	platform       string
-	jwtSecret      string
-	polkaKey       string
+	fileserverHits  atomic.Int32
+	db              *database.Queries
+	dbConn          *sql.DB
+	platform        string
+	keyring         *auth.Keyring
+	denylist        auth.Denylist
+	polkaKey        string
+	adminKey        string
+	chirpEditWindow time.Duration
+	moderator       moderation.Moderator
+	blobStore       media.BlobStore
+	loginFailures   throttle.Store
+	passwordPolicy  auth.PasswordPolicy
+	argon2Params    auth.PasswordHashParams
+	mailer          mail.Mailer
+	appURL          string
 }
 
 func main() {
@@ -33,27 +53,141 @@ func main() {
 	if platform == "" {
 		log.Fatal("PLATFORM must be set")
 	}
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET must be set")
+	keyring, err := loadKeyring()
+	if err != nil {
+		log.Fatalf("Error loading JWT keys: %s", err)
 	}
 	polkaKey := os.Getenv("POLKA_KEY")
 	if polkaKey == "" {
 		log.Fatal("POLKA_KEY must be set")
 	}
 
+	// Without ADMIN_API_KEY the key protected admin endpoints are disabled.
+	adminKey := os.Getenv("ADMIN_API_KEY")
+
+	chirpEditWindow := time.Hour
+	if chirpEditWindowString := os.Getenv("CHIRP_EDIT_WINDOW"); chirpEditWindowString != "" {
+		chirpEditWindow, err = time.ParseDuration(chirpEditWindowString)
+		if err != nil {
+			log.Fatalf("Invalid CHIRP_EDIT_WINDOW: %s", err)
+		}
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "media"
+	}
+	blobStore, err := media.NewFileStore(mediaDir)
+	if err != nil {
+		log.Fatalf("Error opening media directory: %s", err)
+	}
+
+	passwordPolicy, err := loadPasswordPolicy()
+	if err != nil {
+		log.Fatalf("Error loading password policy: %s", err)
+	}
+
+	argon2Params, err := loadArgon2Params()
+	if err != nil {
+		log.Fatalf("Invalid Argon2 parameters: %s", err)
+	}
+
+	mailer, err := loadMailer()
+	if err != nil {
+		log.Fatalf("Error setting up mailer: %s", err)
+	}
+	// Links in emails point to the web client.
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:" + port + "/app"
+	}
+
 	dbConn, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		log.Fatalf("Error opening database: %s", err)
 	}
 	dbQueries := database.New(dbConn)
 
+	var denylist auth.Denylist
+	switch denylistStore := os.Getenv("TOKEN_DENYLIST_STORE"); denylistStore {
+	case "", "postgres":
+		denylist = dbDenylist{db: dbQueries}
+	case "memory":
+		denylist = auth.NewMemoryDenylist()
+	default:
+		log.Fatalf("Invalid TOKEN_DENYLIST_STORE: %s", denylistStore)
+	}
+	go func() {
+		for range time.Tick(denylistPruneInterval) {
+			err := denylist.Prune(context.Background())
+			if err != nil {
+				log.Printf("Error pruning access token denylist: %s", err)
+			}
+		}
+	}()
+
+	var loginFailures throttle.Store
+	switch loginThrottleStore := os.Getenv("LOGIN_THROTTLE_STORE"); loginThrottleStore {
+	case "", "postgres":
+		loginFailures = dbLoginFailureStore{db: dbQueries}
+	case "memory":
+		loginFailures = throttle.NewMemoryStore()
+	default:
+		log.Fatalf("Invalid LOGIN_THROTTLE_STORE: %s", loginThrottleStore)
+	}
+	go func() {
+		for range time.Tick(loginThrottlePruneInterval) {
+			err := loginFailures.Prune(context.Background(), time.Now().UTC().Add(-loginFailureResetAfter))
+			if err != nil {
+				log.Printf("Error pruning failed logins: %s", err)
+			}
+		}
+	}()
+
+	dbWordStage, err := moderation.NewRuleStage(context.Background(), dbWordSource{db: dbQueries})
+	if err != nil {
+		log.Fatalf("Error loading moderation words: %s", err)
+	}
+	moderator := moderation.NewPipeline(dbWordStage)
+	if rulesFile := os.Getenv("MODERATION_RULES_FILE"); rulesFile != "" {
+		fileStage, err := moderation.NewRuleStage(context.Background(), moderation.FileSource{Path: rulesFile})
+		if err != nil {
+			log.Fatalf("Error loading moderation rules file: %s", err)
+		}
+		moderator.Stages = append(moderator.Stages, fileStage)
+	}
+
+	// SIGHUP reloads moderation rules, e.g. after editing MODERATION_RULES_FILE.
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	go func() {
+		for range reloadSignals {
+			err := moderator.Reload(context.Background())
+			if err != nil {
+				log.Printf("Error reloading moderation rules: %s", err)
+				continue
+			}
+			log.Println("Reloaded moderation rules")
+		}
+	}()
+
 	apiCfg := apiConfig{
-		fileserverHits: atomic.Int32{},
-		db:             dbQueries,
-		platform:       platform,
-		jwtSecret:      jwtSecret,
-		polkaKey:       polkaKey,
+		fileserverHits:  atomic.Int32{},
+		db:              dbQueries,
+		dbConn:          dbConn,
+		platform:        platform,
+		keyring:         keyring,
+		denylist:        denylist,
+		polkaKey:        polkaKey,
+		adminKey:        adminKey,
+		chirpEditWindow: chirpEditWindow,
+		moderator:       moderator,
+		blobStore:       blobStore,
+		loginFailures:   loginFailures,
+		passwordPolicy:  passwordPolicy,
+		argon2Params:    argon2Params,
+		mailer:          mailer,
+		appURL:          appURL,
 	}
 
 	mux := http.NewServeMux()
@@ -61,22 +195,67 @@ func main() {
 	mux.Handle("/app/", fsHandler)
 
 	mux.HandleFunc("GET /api/healthz", handlerReadiness)
+	mux.HandleFunc("GET /.well-known/jwks.json", apiCfg.handlerJWKS)
 	mux.HandleFunc("GET /api/chirps", apiCfg.handlerChirpsRetrieve)
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.handlerChirpsSearch)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handlerChirpsGet)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handlerChirpsThread)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", apiCfg.handlerChirpsRevisions)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.handlerChirpsUpdate)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerChirpsDelete)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/like", apiCfg.handlerChirpsLike)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", apiCfg.handlerChirpsUnlike)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.handlerChirpsRechirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.handlerChirpsUnrechirp)
+
+	mux.HandleFunc("POST /api/media", apiCfg.handlerMediaUpload)
+	mux.HandleFunc("GET /api/media/{mediaID}", apiCfg.handlerMediaGet)
+	mux.HandleFunc("GET /api/media/{mediaID}/thumbnail", apiCfg.handlerMediaThumbnailGet)
 
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.handlerWebhook)
 
 	mux.HandleFunc("POST /api/users", apiCfg.handlerUsersCreate)
+	mux.HandleFunc("PATCH /api/users", apiCfg.handlerUsersUpdate)
+	// PUT predates partial updates and is kept for older clients.
 	mux.HandleFunc("PUT /api/users", apiCfg.handlerUsersUpdate)
+	mux.HandleFunc("POST /api/users/verify-email", apiCfg.handlerEmailVerify)
+	mux.HandleFunc("POST /api/users/verify-email/resend", apiCfg.handlerEmailVerificationResend)
+	mux.HandleFunc("POST /api/users/2fa/enroll", apiCfg.handlerTOTPEnroll)
+	mux.HandleFunc("POST /api/users/2fa/confirm", apiCfg.handlerTOTPConfirm)
+	mux.HandleFunc("DELETE /api/users/2fa", apiCfg.handlerTOTPDisable)
+	mux.HandleFunc("GET /api/users/{handle}", apiCfg.handlerProfileGet)
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.handlerFollow)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.handlerUnfollow)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handlerFollowersGet)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handlerFollowingGet)
+	mux.HandleFunc("GET /api/users/{userID}/likes", apiCfg.handlerUserLikesGet)
+	mux.HandleFunc("GET /api/users/{userID}/mentions", apiCfg.handlerUserMentionsGet)
+
+	mux.HandleFunc("GET /api/hashtags/{tag}", apiCfg.handlerHashtagChirpsGet)
+
+	mux.HandleFunc("GET /api/timeline", apiCfg.handlerTimeline)
 
 	mux.HandleFunc("POST /api/chirps", apiCfg.handlerChirpsCreate)
 	mux.HandleFunc("POST /api/login", apiCfg.handlerLogin)
+	mux.HandleFunc("POST /api/login/2fa", apiCfg.handlerLogin2FA)
+	mux.HandleFunc("POST /api/password/forgot", apiCfg.handlerPasswordForgot)
+	mux.HandleFunc("POST /api/password/reset", apiCfg.handlerPasswordReset)
 	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefresh)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevoke)
+	mux.HandleFunc("GET /api/sessions", apiCfg.handlerSessionsGet)
+	mux.HandleFunc("DELETE /api/sessions/{sessionID}", apiCfg.handlerSessionsDelete)
+	mux.HandleFunc("POST /api/sessions/revoke-all", apiCfg.handlerSessionsRevokeAll)
 
 	mux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
 	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerMetrics)
+	mux.HandleFunc("GET /admin/login-lockouts", apiCfg.handlerLoginLockoutsGet)
+	mux.HandleFunc("POST /admin/login-lockouts/{lockoutID}/review", apiCfg.handlerLoginLockoutsReview)
+	mux.HandleFunc("GET /admin/moderation/words", apiCfg.handlerModerationWordsGet)
+	mux.HandleFunc("PUT /admin/moderation/words/{word}", apiCfg.handlerModerationWordsPut)
+	mux.HandleFunc("DELETE /admin/moderation/words/{word}", apiCfg.handlerModerationWordsDelete)
+	mux.HandleFunc("POST /admin/moderation/reload", apiCfg.handlerModerationReload)
+	mux.HandleFunc("GET /admin/moderation/flags", apiCfg.handlerModerationFlagsGet)
+	mux.HandleFunc("POST /admin/moderation/flags/{flagID}/review", apiCfg.handlerModerationFlagsReview)
 
 	srv := &http.Server{
 		Addr:    ":" + port,
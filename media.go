@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+// maxChirpMedia is how many media a single chirp can carry.
+const maxChirpMedia = 4
+
+type Media struct {
+	ID           uuid.UUID `json:"id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	ContentType  string    `json:"content_type"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+}
+
+func databaseMediaToMedia(dbMedia database.Medium) Media {
+	return Media{
+		ID:           dbMedia.ID,
+		URL:          "/api/media/" + dbMedia.ID.String(),
+		ThumbnailURL: "/api/media/" + dbMedia.ID.String() + "/thumbnail",
+		ContentType:  dbMedia.ContentType,
+		Width:        dbMedia.Width,
+		Height:       dbMedia.Height,
+	}
+}
+
+// checkAttachableMedia makes sure userID may attach mediaIDs to a new chirp:
+// there aren't too many, each belongs to the user and none is already
+// attached to another chirp.
+func (cfg *apiConfig) checkAttachableMedia(ctx context.Context, userID uuid.UUID, mediaIDs []uuid.UUID) error {
+	if len(mediaIDs) == 0 {
+		return nil
+	}
+	if len(mediaIDs) > maxChirpMedia {
+		return chirpValidationError{Message: fmt.Sprintf("A chirp can have at most %d media", maxChirpMedia)}
+	}
+
+	seen := make(map[uuid.UUID]struct{}, len(mediaIDs))
+	for _, id := range mediaIDs {
+		if _, ok := seen[id]; ok {
+			return chirpValidationError{Message: "Media can only be attached once"}
+		}
+		seen[id] = struct{}{}
+	}
+
+	attachable, err := cfg.db.GetAttachableMedia(ctx, database.GetAttachableMediaParams{
+		Ids:    mediaIDs,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if len(attachable) != len(mediaIDs) {
+		return chirpValidationError{Message: "Media doesn't exist, isn't yours or is already attached"}
+	}
+	return nil
+}
+
+// setChirpMedia fills in Media on the given chirps with a single query.
+func (cfg *apiConfig) setChirpMedia(ctx context.Context, chirps ...*Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+	rows, err := cfg.db.GetMediaForChirps(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+
+	byChirp := make(map[uuid.UUID][]Media, len(rows))
+	for _, row := range rows {
+		byChirp[row.ChirpID] = append(byChirp[row.ChirpID], databaseMediaToMedia(row.Medium))
+	}
+	for _, chirp := range chirps {
+		if media, ok := byChirp[chirp.ID]; ok {
+			chirp.Media = media
+		}
+	}
+	return nil
+}
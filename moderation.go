@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/moderation"
+)
+
+// dbWordSource serves the word list that admins manage through
+// /admin/moderation/words.
+type dbWordSource struct {
+	db *database.Queries
+}
+
+func (s dbWordSource) LoadRules(ctx context.Context) ([]moderation.Rule, error) {
+	words, err := s.db.GetModerationWords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]moderation.Rule, 0, len(words))
+	for _, word := range words {
+		rules = append(rules, moderation.Rule{
+			Kind:    moderation.RuleKindWord,
+			Pattern: word.Word,
+			Action:  moderation.Action(word.Action),
+		})
+	}
+	return rules, nil
+}
+
+// recordModerationFlags queues a chirp for review once per rule that flagged it.
+func recordModerationFlags(ctx context.Context, qtx *database.Queries, chirpID uuid.UUID, result moderation.Result) error {
+	for _, rule := range result.FlaggedBy {
+		err := qtx.CreateModerationFlag(ctx, database.CreateModerationFlagParams{
+			ChirpID: chirpID,
+			Rule:    rule,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
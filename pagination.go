@@ -0,0 +1,130 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageCursor marks the last row of a page. Rows are ordered by
+// (created_at, id) so the cursor is stable even when timestamps collide.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func encodeCursor(c pageCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, err
+	}
+	createdAtString, idString, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtString)
+	if err != nil {
+		return pageCursor{}, err
+	}
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		return pageCursor{}, err
+	}
+	return pageCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// cursorParams converts an optional cursor into the nullable parameters taken
+// by the keyset queries.
+func cursorParams(c *pageCursor) (sql.NullTime, uuid.NullUUID) {
+	if c == nil {
+		return sql.NullTime{}, uuid.NullUUID{}
+	}
+	return sql.NullTime{Time: c.CreatedAt, Valid: true}, uuid.NullUUID{UUID: c.ID, Valid: true}
+}
+
+// encodeOffsetCursor is used where results have no stable keyset to page
+// on, such as search results ordered by relevance.
+func encodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset|" + strconv.Itoa(offset)))
+}
+
+func decodeOffsetCursor(s string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	offsetString, ok := strings.CutPrefix(string(raw), "offset|")
+	if !ok {
+		return 0, errors.New("malformed cursor")
+	}
+	offset, err := strconv.Atoi(offsetString)
+	if err != nil || offset < 0 {
+		return 0, errors.New("malformed cursor")
+	}
+	return offset, nil
+}
+
+func parsePageLimit(r *http.Request) (int, error) {
+	limitString := r.URL.Query().Get("limit")
+	if limitString == "" {
+		return defaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(limitString)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, errors.New("Limit must be between 1 and " + strconv.Itoa(maxPageLimit))
+	}
+	return limit, nil
+}
+
+// parsePageParams reads the limit and cursor query parameters. A missing
+// cursor means "start from the beginning" and is returned as nil.
+func parsePageParams(r *http.Request) (int, *pageCursor, error) {
+	limit, err := parsePageLimit(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	cursorString := r.URL.Query().Get("cursor")
+	if cursorString == "" {
+		return limit, nil, nil
+	}
+	cursor, err := decodeCursor(cursorString)
+	if err != nil {
+		return 0, nil, errors.New("Invalid cursor")
+	}
+	return limit, &cursor, nil
+}
+
+// parseOffsetPageParams is parsePageParams for offset cursors.
+func parseOffsetPageParams(r *http.Request) (int, int, error) {
+	limit, err := parsePageLimit(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cursorString := r.URL.Query().Get("cursor")
+	if cursorString == "" {
+		return limit, 0, nil
+	}
+	offset, err := decodeOffsetCursor(cursorString)
+	if err != nil {
+		return 0, 0, errors.New("Invalid cursor")
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gooneraki/chirpy-go/internal/auth"
+)
+
+const (
+	defaultPasswordMinLength  = 8
+	defaultPasswordMinEntropy = 40
+)
+
+// loadPasswordPolicy sets up the password policy from the environment:
+// PASSWORD_MIN_LENGTH, PASSWORD_MIN_ENTROPY in bits, and
+// BREACHED_PASSWORDS_FILE, a Pwned Passwords style list of SHA-1 hashes
+// that is only checked if set.
+func loadPasswordPolicy() (auth.PasswordPolicy, error) {
+	policy := auth.PasswordPolicy{
+		MinLength:      defaultPasswordMinLength,
+		MinEntropyBits: defaultPasswordMinEntropy,
+	}
+
+	if minLengthString := os.Getenv("PASSWORD_MIN_LENGTH"); minLengthString != "" {
+		minLength, err := strconv.Atoi(minLengthString)
+		if err != nil || minLength < 1 {
+			return auth.PasswordPolicy{}, fmt.Errorf("invalid PASSWORD_MIN_LENGTH: %s", minLengthString)
+		}
+		policy.MinLength = minLength
+	}
+	if minEntropyString := os.Getenv("PASSWORD_MIN_ENTROPY"); minEntropyString != "" {
+		minEntropy, err := strconv.ParseFloat(minEntropyString, 64)
+		if err != nil || minEntropy < 0 {
+			return auth.PasswordPolicy{}, fmt.Errorf("invalid PASSWORD_MIN_ENTROPY: %s", minEntropyString)
+		}
+		policy.MinEntropyBits = minEntropy
+	}
+	if breachedFile := os.Getenv("BREACHED_PASSWORDS_FILE"); breachedFile != "" {
+		breached, err := auth.OpenBreachedPasswordFile(breachedFile)
+		if err != nil {
+			return auth.PasswordPolicy{}, err
+		}
+		policy.Breached = breached
+	}
+	return policy, nil
+}
+
+// respondWithPasswordPolicyError reports an error from
+// auth.PasswordPolicy.Check, naming the rule the password broke.
+func respondWithPasswordPolicyError(w http.ResponseWriter, err error) {
+	type policyResponse struct {
+		Error string            `json:"error"`
+		Rule  auth.PasswordRule `json:"rule"`
+	}
+
+	var policyErr *auth.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check password", err)
+		return
+	}
+	respondWithJSON(w, http.StatusBadRequest, policyResponse{
+		Error: policyErr.Message,
+		Rule:  policyErr.Rule,
+	})
+}
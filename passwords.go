@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+// loadArgon2Params reads the Argon2id parameters new passwords are
+// hashed with from ARGON2_MEMORY in KiB, ARGON2_ITERATIONS and
+// ARGON2_PARALLELISM. Each defaults to auth.DefaultPasswordHashParams; go
+// run ./cmd/argon2tune suggests values for this machine.
+func loadArgon2Params() (auth.PasswordHashParams, error) {
+	params := auth.DefaultPasswordHashParams
+	for _, setting := range []struct {
+		env  string
+		bits int
+		set  func(uint64)
+	}{
+		{"ARGON2_MEMORY", 32, func(v uint64) { params.Memory = uint32(v) }},
+		{"ARGON2_ITERATIONS", 32, func(v uint64) { params.Iterations = uint32(v) }},
+		{"ARGON2_PARALLELISM", 8, func(v uint64) { params.Parallelism = uint8(v) }},
+	} {
+		valueString := os.Getenv(setting.env)
+		if valueString == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(valueString, 10, setting.bits)
+		if err != nil {
+			return auth.PasswordHashParams{}, fmt.Errorf("invalid %s: %s", setting.env, valueString)
+		}
+		setting.set(value)
+	}
+	return params, params.Validate()
+}
+
+// hashPassword hashes a new password with the configured parameters.
+func (cfg *apiConfig) hashPassword(password string) (string, error) {
+	return auth.HashPasswordWithParams(password, cfg.argon2Params)
+}
+
+// upgradePasswordHash hashes password again if the user's hash was made with
+// other parameters than the configured ones, so raising them strengthens
+// every account as its user logs in. It must only be called with the
+// password that matched the hash. Failing to upgrade doesn't stop the
+// login, so errors are only logged.
+func (cfg *apiConfig) upgradePasswordHash(ctx context.Context, user database.User, password string) {
+	needsRehash, err := auth.PasswordNeedsRehash(user.HashedPassword, cfg.argon2Params)
+	if err != nil || !needsRehash {
+		return
+	}
+	hashedPassword, err := cfg.hashPassword(password)
+	if err != nil {
+		log.Printf("Error rehashing password: %s", err)
+		return
+	}
+	// Only replace the hash the password was checked against, in case the
+	// password changed in the meantime.
+	_, err = cfg.db.RehashUserPassword(ctx, database.RehashUserPasswordParams{
+		ID:      user.ID,
+		NewHash: hashedPassword,
+		OldHash: user.HashedPassword,
+	})
+	if err != nil {
+		log.Printf("Error saving rehashed password: %s", err)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/chirptext"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+)
+
+// Profile is what anyone can see about a user. It never includes the email.
+type Profile struct {
+	ID            uuid.UUID     `json:"id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Handle        string        `json:"handle"`
+	DisplayName   string        `json:"display_name"`
+	Bio           string        `json:"bio"`
+	AvatarMediaID uuid.NullUUID `json:"avatar_media_id"`
+	// AvatarURL is empty when the user has no avatar.
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	IsChirpyRed bool   `json:"is_chirpy_red"`
+}
+
+func databaseUserToProfile(user database.User) Profile {
+	profile := Profile{
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		Handle:        user.Handle,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		AvatarMediaID: user.AvatarMediaID,
+		IsChirpyRed:   user.IsChirpyRed,
+	}
+	if user.AvatarMediaID.Valid {
+		profile.AvatarURL = "/api/media/" + user.AvatarMediaID.UUID.String()
+	}
+	return profile
+}
+
+// defaultHandle is the handle of users who didn't pick one at sign-up, like
+// the ones existing accounts got in the 024_profiles migration.
+func defaultHandle() string {
+	suffix := make([]byte, 5)
+	rand.Read(suffix)
+	return "user_" + hex.EncodeToString(suffix)
+}
+
+// errHandleTaken -
+var errHandleTaken = errors.New("handle is already taken")
+
+// profileValidationError is a problem with a profile field that the user
+// has to fix.
+type profileValidationError struct {
+	Message string
+}
+
+func (e profileValidationError) Error() string {
+	return e.Message
+}
+
+// checkHandleAvailable checks that userID may take handle: that it follows
+// the handle rules and nobody else has it in any case.
+func (cfg *apiConfig) checkHandleAvailable(ctx context.Context, userID uuid.UUID, handle string) error {
+	err := chirptext.CheckHandle(handle)
+	if errors.Is(err, chirptext.ErrReservedHandle) {
+		return profileValidationError{Message: "Handle is reserved"}
+	}
+	if err != nil {
+		return profileValidationError{Message: "Invalid handle: " + err.Error()}
+	}
+
+	owner, err := cfg.db.GetUserByHandle(ctx, handle)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if owner.ID != userID {
+		return errHandleTaken
+	}
+	return nil
+}
+
+// normalizeProfileText trims a display name or bio and checks its length,
+// counted and bounded in bytes like a chirp's.
+func normalizeProfileText(field, text string, limit int, multiline bool) (string, error) {
+	text = strings.TrimSpace(text)
+	if !multiline && strings.ContainsAny(text, "\r\n") {
+		return "", profileValidationError{Message: fmt.Sprintf("%s can't contain line breaks", field)}
+	}
+	if len(text) > maxBytesPerCharacter*limit {
+		return "", profileValidationError{Message: fmt.Sprintf("%s is too long: %d bytes, the limit is %d", field, len(text), maxBytesPerCharacter*limit)}
+	}
+	if length := chirptext.Length(text); length > limit {
+		return "", profileValidationError{Message: fmt.Sprintf("%s is too long: %d characters, the limit is %d", field, length, limit)}
+	}
+	return text, nil
+}
+
+// checkAvatar checks that mediaID is an image userID uploaded.
+func (cfg *apiConfig) checkAvatar(ctx context.Context, userID, mediaID uuid.UUID) error {
+	dbMedia, err := cfg.db.GetMedia(ctx, mediaID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && dbMedia.UserID != userID) {
+		return profileValidationError{Message: "Couldn't find avatar media"}
+	}
+	return err
+}
+
+// checkProfileUpdate validates the profile fields of a user update. Fields
+// that are nil are left as they are.
+func (cfg *apiConfig) checkProfileUpdate(ctx context.Context, userID uuid.UUID, handle, displayName, bio, avatarMediaID *string) (database.UpdateUserProfileParams, error) {
+	update := database.UpdateUserProfileParams{ID: userID}
+
+	if handle != nil {
+		err := cfg.checkHandleAvailable(ctx, userID, *handle)
+		if err != nil {
+			return database.UpdateUserProfileParams{}, err
+		}
+		update.Handle = sql.NullString{String: *handle, Valid: true}
+	}
+	if displayName != nil {
+		text, err := normalizeProfileText("Display name", *displayName, maxDisplayNameLength, false)
+		if err != nil {
+			return database.UpdateUserProfileParams{}, err
+		}
+		update.DisplayName = sql.NullString{String: text, Valid: true}
+	}
+	if bio != nil {
+		text, err := normalizeProfileText("Bio", *bio, maxBioLength, true)
+		if err != nil {
+			return database.UpdateUserProfileParams{}, err
+		}
+		update.Bio = sql.NullString{String: text, Valid: true}
+	}
+	if avatarMediaID != nil {
+		update.SetAvatar = true
+		if *avatarMediaID != "" {
+			mediaID, err := uuid.Parse(*avatarMediaID)
+			if err != nil {
+				return database.UpdateUserProfileParams{}, profileValidationError{Message: "Invalid avatar media ID"}
+			}
+			err = cfg.checkAvatar(ctx, userID, mediaID)
+			if err != nil {
+				return database.UpdateUserProfileParams{}, err
+			}
+			update.AvatarMediaID = uuid.NullUUID{UUID: mediaID, Valid: true}
+		}
+	}
+	return update, nil
+}
+
+// setChirpAuthors embeds the profile of each chirp's author.
+func (cfg *apiConfig) setChirpAuthors(ctx context.Context, chirps ...*Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		userIDs = append(userIDs, chirp.UserID)
+	}
+	users, err := cfg.db.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	profiles := make(map[uuid.UUID]Profile, len(users))
+	for _, user := range users {
+		profiles[user.ID] = databaseUserToProfile(user)
+	}
+	for _, chirp := range chirps {
+		if profile, ok := profiles[chirp.UserID]; ok {
+			chirp.Author = &profile
+		}
+	}
+	return nil
+}
+
+// handlerProfileGet shows a user's public profile by handle, with or
+// without the @.
+func (cfg *apiConfig) handlerProfileGet(w http.ResponseWriter, r *http.Request) {
+	handle := strings.TrimPrefix(r.PathValue("handle"), "@")
+
+	user, err := cfg.db.GetUserByHandle(r.Context(), handle)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseUserToProfile(user))
+}
+
+// respondWithProfileValidationError reports an error from checking profile
+// fields.
+func respondWithProfileValidationError(w http.ResponseWriter, err error) {
+	var validationErr profileValidationError
+	if errors.As(err, &validationErr) {
+		respondWithError(w, http.StatusBadRequest, validationErr.Message, err)
+		return
+	}
+	if errors.Is(err, errHandleTaken) {
+		respondWithError(w, http.StatusConflict, "Handle is already taken", err)
+		return
+	}
+	respondWithError(w, http.StatusInternalServerError, "Couldn't check profile", err)
+}
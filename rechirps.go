@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+// resolveOriginalChirp returns the chirp that should be rechirped or quoted
+// when a user picks chirpID. Rechirping or quoting a plain rechirp targets the
+// chirp it shares instead.
+func (cfg *apiConfig) resolveOriginalChirp(ctx context.Context, chirpID uuid.UUID) (database.Chirp, error) {
+	dbChirp, err := cfg.db.GetChirp(ctx, chirpID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if dbChirp.Kind != database.ChirpKindRechirp {
+		return dbChirp, nil
+	}
+	return cfg.db.GetChirp(ctx, dbChirp.OriginalChirpID.UUID)
+}
+
+// embedOriginalChirps sets OriginalChirp on rechirps and quotes with a
+// single query and returns the embedded chirps. Originals that have been
+// deleted are left nil.
+func (cfg *apiConfig) embedOriginalChirps(ctx context.Context, chirps ...*Chirp) ([]*Chirp, error) {
+	ids := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if chirp.OriginalChirpID.Valid {
+			ids = append(ids, chirp.OriginalChirpID.UUID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	dbOriginals, err := cfg.db.GetChirpsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	originals := make(map[uuid.UUID]*Chirp, len(dbOriginals))
+	embedded := make([]*Chirp, 0, len(dbOriginals))
+	for _, dbOriginal := range dbOriginals {
+		original := databaseChirpToChirp(dbOriginal)
+		originals[original.ID] = &original
+		embedded = append(embedded, &original)
+	}
+	for _, chirp := range chirps {
+		if chirp.OriginalChirpID.Valid {
+			chirp.OriginalChirp = originals[chirp.OriginalChirpID.UUID]
+		}
+	}
+	return embedded, nil
+}
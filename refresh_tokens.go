@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+	"github.com/gooneraki/chirpy-go/internal/database"
+)
+
+// refreshTokenDuration is how long a refresh token can be used after it is
+// issued.
+const refreshTokenDuration = 60 * 24 * time.Hour
+
+// maxUserAgentLength keeps clients from storing arbitrarily long strings with
+// their sessions.
+const maxUserAgentLength = 512
+
+// refreshSession is what every refresh token of a session carries over from
+// the login that started it.
+type refreshSession struct {
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	StartedAt time.Time
+	UserAgent string
+	IPAddress string
+}
+
+// newRefreshSession starts a session for a user logging in with r.
+func newRefreshSession(r *http.Request, userID uuid.UUID) refreshSession {
+	// net/http lets any bytes through, but Postgres only stores valid UTF-8.
+	userAgent := strings.ToValidUTF8(r.UserAgent(), "\uFFFD")
+	if len(userAgent) > maxUserAgentLength {
+		end := maxUserAgentLength
+		for !utf8.RuneStart(userAgent[end]) {
+			end--
+		}
+		userAgent = userAgent[:end]
+	}
+	return refreshSession{
+		UserID:    userID,
+		FamilyID:  uuid.New(),
+		StartedAt: time.Now().UTC(),
+		UserAgent: userAgent,
+		IPAddress: clientIP(r),
+	}
+}
+
+// refreshSessionOf returns the session a refresh token belongs to.
+func refreshSessionOf(dbToken database.RefreshToken) refreshSession {
+	return refreshSession{
+		UserID:    dbToken.UserID,
+		FamilyID:  dbToken.FamilyID,
+		StartedAt: dbToken.SessionStartedAt,
+		UserAgent: dbToken.UserAgent,
+		IPAddress: dbToken.IpAddress,
+	}
+}
+
+// createRefreshToken issues the next refresh token of a session, along with
+// the access token with accessTokenID. Logging in starts a new family of
+// tokens and every refresh adds the next token to it, so a family is one
+// session. Only the token's hash is saved.
+func createRefreshToken(ctx context.Context, q *database.Queries, session refreshSession, accessTokenID string) (string, error) {
+	refreshToken := auth.MakeRefreshToken()
+	_, err := q.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{
+		TokenHash:        auth.HashRefreshToken(refreshToken),
+		UserID:           session.UserID,
+		ExpiresAt:        time.Now().UTC().Add(refreshTokenDuration),
+		FamilyID:         session.FamilyID,
+		SessionStartedAt: session.StartedAt,
+		UserAgent:        session.UserAgent,
+		IpAddress:        session.IPAddress,
+		AccessTokenJti:   accessTokenID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return refreshToken, nil
+}
+
+// revokeUserSessions logs out all of a user's sessions except the one with
+// exceptFamilyID, if it is set, and revokes their access tokens.
+func (cfg *apiConfig) revokeUserSessions(ctx context.Context, userID uuid.UUID, exceptFamilyID uuid.NullUUID) error {
+	err := cfg.db.RevokeUserSessions(ctx, database.RevokeUserSessionsParams{
+		UserID:         userID,
+		ExceptFamilyID: exceptFamilyID,
+	})
+	if err != nil {
+		return err
+	}
+	return cfg.denyUserAccessTokens(ctx, userID, exceptFamilyID)
+}
+
+// clientIP is the address the request came from. Chirpy doesn't trust
+// X-Forwarded-For, since any client can set it.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,56 @@
+-- name: CreateChirpMention :exec
+INSERT INTO chirp_mentions (chirp_id, user_id, handle, start_index, end_index)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: CreateChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, tag, start_index, end_index)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: GetChirpMentions :many
+SELECT * FROM chirp_mentions
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, start_index;
+
+-- name: GetChirpHashtags :many
+SELECT * FROM chirp_hashtags
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, start_index;
+
+-- name: GetChirpsMentioningUser :many
+SELECT * FROM chirps
+WHERE id IN (SELECT m.chirp_id FROM chirp_mentions m WHERE m.user_id = sqlc.arg('user_id'))
+AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpsWithHashtag :many
+SELECT * FROM chirps
+WHERE id IN (SELECT h.chirp_id FROM chirp_hashtags h WHERE h.tag = sqlc.arg('tag'))
+AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
@@ -1,22 +1,152 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to_id, kind, original_chirp_id)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, kind, original_chirp_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    '',
+    $1,
+    'rechirp',
     $2
 )
+ON CONFLICT (user_id, original_chirp_id) WHERE kind = 'rechirp' DO NOTHING
 RETURNING *;
 
--- name: GetChirps :many
-SELECT * FROM chirps ORDER BY created_at;
+-- name: GetRechirp :one
+SELECT * FROM chirps
+WHERE user_id = $1 AND original_chirp_id = $2 AND kind = 'rechirp';
 
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
 
+-- name: GetChirpsAsc :many
+SELECT * FROM chirps
+WHERE (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpsDesc :many
+SELECT * FROM chirps
+WHERE (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
 
 -- name: GetChirp :one
 SELECT * FROM chirps WHERE id=$1;
 
+-- name: GetChirpForUpdate :one
+SELECT * FROM chirps WHERE id=$1
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps SET body = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
 -- name: DeleteChirp :exec
 DELETE FROM chirps
+WHERE id = $1
+OR (original_chirp_id = $1 AND kind = 'rechirp');
+
+-- name: IncrementReplyCount :exec
+UPDATE chirps SET reply_count = reply_count + 1
 WHERE id = $1;
+
+-- name: DecrementReplyCount :exec
+UPDATE chirps SET reply_count = reply_count - 1
+WHERE id = $1 AND reply_count > 0;
+
+-- name: IncrementRechirpCount :exec
+UPDATE chirps SET rechirp_count = rechirp_count + 1
+WHERE id = $1;
+
+-- name: DecrementRechirpCount :exec
+UPDATE chirps SET rechirp_count = rechirp_count - 1
+WHERE id = $1 AND rechirp_count > 0;
+
+-- name: IncrementQuoteCount :exec
+UPDATE chirps SET quote_count = quote_count + 1
+WHERE id = $1;
+
+-- name: DecrementQuoteCount :exec
+UPDATE chirps SET quote_count = quote_count - 1
+WHERE id = $1 AND quote_count > 0;
+
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT parent.id, parent.in_reply_to_id, 1 AS depth FROM chirps parent
+    WHERE parent.id = (SELECT child.in_reply_to_id FROM chirps child WHERE child.id = sqlc.arg('id'))
+    UNION ALL
+    SELECT parent.id, parent.in_reply_to_id, ancestors.depth + 1 FROM chirps parent
+    JOIN ancestors ON parent.id = ancestors.in_reply_to_id
+    WHERE ancestors.depth < sqlc.arg('max_depth')::integer
+)
+SELECT sqlc.embed(chirps), ancestors.depth FROM ancestors
+JOIN chirps ON chirps.id = ancestors.id
+ORDER BY ancestors.depth DESC;
+
+-- name: GetChirpDescendants :many
+WITH RECURSIVE descendants AS (
+    SELECT reply.id, 1 AS depth FROM chirps reply
+    WHERE reply.in_reply_to_id = sqlc.arg('id')::uuid
+    UNION ALL
+    SELECT reply.id, descendants.depth + 1 FROM chirps reply
+    JOIN descendants ON reply.in_reply_to_id = descendants.id
+    WHERE descendants.depth < sqlc.arg('max_depth')::integer
+)
+SELECT sqlc.embed(chirps), descendants.depth FROM descendants
+JOIN chirps ON chirps.id = descendants.id
+ORDER BY descendants.depth, chirps.created_at, chirps.id
+LIMIT sqlc.arg('limit');
+
+-- name: SearchChirps :many
+SELECT
+    sqlc.embed(chirps),
+    ts_rank_cd(chirps.search_vector, query)::real AS rank,
+    ts_headline('english', chirps.body, query, sqlc.arg('headline_options')::text)::text AS snippet
+FROM chirps, to_tsquery('english', sqlc.arg('query')::text) AS query
+WHERE chirps.search_vector @@ query
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id')::uuid)
+ORDER BY
+    CASE WHEN sqlc.arg('sort')::text = 'asc' THEN chirps.created_at END ASC,
+    CASE WHEN sqlc.arg('sort')::text = 'desc' THEN chirps.created_at END DESC,
+    rank DESC,
+    chirps.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetTimeline :many
+SELECT * FROM chirps
+WHERE (
+    user_id = sqlc.arg('user_id')
+    OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id'))
+)
+AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,32 @@
+-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (token_hash, user_id, email, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    NOW(),
+    $4
+);
+
+-- name: GetEmailVerificationTokenForUpdate :one
+SELECT * FROM email_verification_tokens
+WHERE token_hash = $1
+FOR UPDATE;
+
+-- name: UseEmailVerificationTokens :exec
+UPDATE email_verification_tokens SET used_at = NOW()
+WHERE user_id = $1
+AND used_at IS NULL;
+
+-- name: GetPendingEmail :one
+SELECT email FROM email_verification_tokens
+WHERE user_id = $1
+AND used_at IS NULL
+AND expires_at > NOW()
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: CountRecentEmailVerificationTokens :one
+SELECT COUNT(*) FROM email_verification_tokens
+WHERE user_id = sqlc.arg('user_id')
+AND created_at > sqlc.arg('since')::timestamp;
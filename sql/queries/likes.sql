@@ -0,0 +1,38 @@
+-- name: LikeChirp :execrows
+INSERT INTO likes (user_id, chirp_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: UnlikeChirp :execrows
+DELETE FROM likes
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: IncrementLikeCount :one
+UPDATE chirps SET like_count = like_count + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: DecrementLikeCount :one
+UPDATE chirps SET like_count = GREATEST(like_count - 1, 0)
+WHERE id = $1
+RETURNING *;
+
+-- name: GetLikedChirpIDs :many
+SELECT chirp_id FROM likes
+WHERE user_id = sqlc.arg('user_id')
+AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetUserLikes :many
+SELECT sqlc.embed(chirps), likes.created_at AS liked_at FROM likes
+JOIN chirps ON chirps.id = likes.chirp_id
+WHERE likes.user_id = sqlc.arg('user_id')
+AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (likes.created_at, likes.chirp_id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+)
+ORDER BY likes.created_at DESC, likes.chirp_id DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,63 @@
+-- name: GetLoginFailures :one
+SELECT * FROM login_failures
+WHERE key = $1;
+
+-- name: AttemptLogin :one
+INSERT INTO login_failures (key, failures, last_failure_at)
+VALUES (
+    sqlc.arg('key'),
+    1,
+    sqlc.arg('now')::timestamp
+)
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE
+        WHEN login_failures.last_failure_at < sqlc.arg('since')::timestamp THEN 1
+        ELSE login_failures.failures + 1
+    END,
+    last_failure_at = EXCLUDED.last_failure_at,
+    previous_failure_at = login_failures.last_failure_at
+RETURNING *;
+
+-- name: RefundLoginAttempt :exec
+UPDATE login_failures SET
+    failures = GREATEST(failures - 1, 0),
+    last_failure_at = CASE
+        WHEN last_failure_at = sqlc.arg('attempted_at')::timestamp THEN sqlc.arg('previous_failure_at')::timestamp
+        ELSE last_failure_at
+    END
+WHERE key = sqlc.arg('key');
+
+-- name: ResetLoginFailures :exec
+DELETE FROM login_failures
+WHERE key = $1;
+
+-- name: PruneLoginFailures :execrows
+DELETE FROM login_failures
+WHERE last_failure_at < $1;
+
+-- name: CreateLoginLockout :exec
+INSERT INTO login_lockouts (id, email, user_id, ip_address, failures, locked_until, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    NOW()
+);
+
+-- name: GetUnreviewedLoginLockouts :many
+SELECT * FROM login_lockouts
+WHERE reviewed_at IS NULL
+AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: ReviewLoginLockout :one
+UPDATE login_lockouts SET reviewed_at = NOW()
+WHERE id = $1
+RETURNING *;
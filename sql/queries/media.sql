@@ -0,0 +1,39 @@
+-- name: CreateMedia :one
+INSERT INTO media (id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key, thumbnail_content_type)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING *;
+
+-- name: GetMedia :one
+SELECT * FROM media WHERE id = $1;
+
+-- name: GetAttachableMedia :many
+SELECT media.* FROM media
+LEFT JOIN chirp_media ON chirp_media.media_id = media.id
+WHERE media.id = ANY(sqlc.arg('ids')::uuid[])
+AND media.user_id = sqlc.arg('user_id')
+AND chirp_media.media_id IS NULL;
+
+-- name: AttachMedia :exec
+INSERT INTO chirp_media (chirp_id, media_id, position)
+VALUES (
+    $1,
+    $2,
+    $3
+);
+
+-- name: GetMediaForChirps :many
+SELECT chirp_media.chirp_id, sqlc.embed(media) FROM chirp_media
+JOIN media ON media.id = chirp_media.media_id
+WHERE chirp_media.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_media.chirp_id, chirp_media.position;
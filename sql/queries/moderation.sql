@@ -0,0 +1,43 @@
+-- name: GetModerationWords :many
+SELECT * FROM moderation_words
+ORDER BY word;
+
+-- name: UpsertModerationWord :one
+INSERT INTO moderation_words (word, action, created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW()
+)
+ON CONFLICT (word) DO UPDATE
+SET action = EXCLUDED.action, updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteModerationWord :execrows
+DELETE FROM moderation_words
+WHERE word = $1;
+
+-- name: CreateModerationFlag :exec
+INSERT INTO moderation_flags (id, chirp_id, rule, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+);
+
+-- name: GetUnreviewedModerationFlags :many
+SELECT * FROM moderation_flags
+WHERE reviewed_at IS NULL
+AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: ReviewModerationFlag :one
+UPDATE moderation_flags SET reviewed_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -1,23 +1,71 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at)
+INSERT INTO refresh_tokens (token_hash, created_at, updated_at, user_id, expires_at, family_id, session_started_at, last_used_at, user_agent, ip_address, access_token_jti)
 VALUES (
-    $1,
+    sqlc.arg('token_hash'),
     NOW(),
     NOW(),
-    $2,
-    $3
+    sqlc.arg('user_id'),
+    sqlc.arg('expires_at'),
+    sqlc.arg('family_id'),
+    sqlc.arg('session_started_at'),
+    NOW(),
+    sqlc.arg('user_agent'),
+    sqlc.arg('ip_address'),
+    sqlc.arg('access_token_jti')::text
 )
 RETURNING *;
 
--- name: RevokeRefreshToken :one
+-- name: GetRefreshToken :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1;
+
+-- name: GetRefreshTokenForUpdate :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1
+FOR UPDATE;
+
+-- name: RotateRefreshToken :exec
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW(),
+replaced_by = sqlc.arg('replaced_by')::text
+WHERE token_hash = sqlc.arg('token_hash');
+
+-- name: RevokeRefreshTokenFamily :exec
 UPDATE refresh_tokens SET revoked_at = NOW(),
 updated_at = NOW()
-WHERE token = $1
-RETURNING *;
+WHERE family_id = $1
+AND revoked_at IS NULL;
 
--- name: GetUserFromRefreshToken :one
-SELECT users.* FROM users
-JOIN refresh_tokens ON users.id = refresh_tokens.user_id
-WHERE refresh_tokens.token = $1
+-- name: GetActiveSessions :many
+SELECT family_id, session_started_at, last_used_at, user_agent, ip_address FROM refresh_tokens
+WHERE user_id = $1
 AND revoked_at IS NULL
-AND expires_at > NOW();
+AND expires_at > NOW()
+ORDER BY last_used_at DESC, family_id;
+
+-- name: RevokeSession :execrows
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE family_id = $1
+AND user_id = $2
+AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE user_id = $1
+AND revoked_at IS NULL
+AND (sqlc.narg('except_family_id')::uuid IS NULL OR family_id <> sqlc.narg('except_family_id')::uuid);
+
+-- name: GetSessionAccessTokens :many
+SELECT access_token_jti::text AS jti, created_at FROM refresh_tokens
+WHERE family_id = $1
+AND access_token_jti IS NOT NULL
+AND created_at > sqlc.arg('issued_after');
+
+-- name: GetUserAccessTokens :many
+SELECT access_token_jti::text AS jti, created_at FROM refresh_tokens
+WHERE user_id = $1
+AND access_token_jti IS NOT NULL
+AND created_at > sqlc.arg('issued_after')
+AND (sqlc.narg('except_family_id')::uuid IS NULL OR family_id <> sqlc.narg('except_family_id')::uuid);
@@ -0,0 +1,40 @@
+-- name: SetPendingTOTPSecret :exec
+UPDATE users SET totp_secret = sqlc.arg('totp_secret')::text, totp_enabled_at = NULL, totp_last_step = 0, updated_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: EnableTOTP :exec
+UPDATE users SET totp_enabled_at = NOW(), totp_last_step = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: DisableTOTP :exec
+UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UseTOTPStep :execrows
+UPDATE users SET totp_last_step = sqlc.arg('step')
+WHERE id = sqlc.arg('id')
+AND totp_last_step < sqlc.arg('step');
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (id, user_id, code_hash, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes SET used_at = NOW()
+WHERE user_id = $1
+AND code_hash = $2
+AND used_at IS NULL;
+
+-- name: CountRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE user_id = $1
+AND used_at IS NULL;
@@ -1,20 +1,33 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users WHERE email = $1;
 
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = $1;
 
--- name: UpdateUser :one
-UPDATE users SET email = $2, hashed_password = $3, updated_at = NOW()
+-- name: GetUserByHandle :one
+SELECT * FROM users WHERE LOWER(handle) = LOWER($1);
+
+-- name: GetUsersByIDs :many
+SELECT * FROM users WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: GetUsersByHandles :many
+SELECT * FROM users WHERE LOWER(handle) = ANY(sqlc.arg('handles')::text[]);
+
+
+-- name: VerifyUserEmail :one
+UPDATE users SET email = $2, email_verified_at = NOW(), updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
@@ -23,3 +36,26 @@ RETURNING *;
 UPDATE users SET is_chirpy_red = true, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users SET hashed_password = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RehashUserPassword :execrows
+UPDATE users SET hashed_password = sqlc.arg('new_hash')
+WHERE id = sqlc.arg('id')
+AND hashed_password = sqlc.arg('old_hash');
+
+-- name: UpdateUserProfile :one
+UPDATE users SET
+    handle = COALESCE(sqlc.narg('handle'), handle),
+    display_name = COALESCE(sqlc.narg('display_name'), display_name),
+    bio = COALESCE(sqlc.narg('bio'), bio),
+    avatar_media_id = CASE
+        WHEN sqlc.arg('set_avatar')::bool THEN sqlc.narg('avatar_media_id')::uuid
+        ELSE avatar_media_id
+    END,
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
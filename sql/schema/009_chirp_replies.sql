@@ -0,0 +1,14 @@
+-- +goose Up
+-- in_reply_to_id deliberately has no foreign key: a reply keeps pointing at
+-- its parent after the parent is deleted so threads can show a placeholder.
+ALTER TABLE chirps
+ADD COLUMN in_reply_to_id UUID,
+ADD COLUMN reply_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX chirps_in_reply_to_id_idx ON chirps (in_reply_to_id, created_at, id);
+
+-- +goose Down
+DROP INDEX chirps_in_reply_to_id_idx;
+ALTER TABLE chirps
+DROP COLUMN reply_count,
+DROP COLUMN in_reply_to_id;
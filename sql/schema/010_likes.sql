@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE likes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX likes_user_id_created_at_idx ON likes (user_id, created_at, chirp_id);
+CREATE INDEX likes_chirp_id_idx ON likes (chirp_id);
+
+ALTER TABLE chirps
+ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN like_count;
+DROP TABLE likes;
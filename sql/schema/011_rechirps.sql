@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TYPE chirp_kind AS ENUM ('chirp', 'rechirp', 'quote');
+
+-- Like in_reply_to_id, original_chirp_id has no foreign key. Deleting a chirp
+-- removes its plain rechirps, but quotes keep their own body and show the
+-- original as unavailable.
+ALTER TABLE chirps
+ADD COLUMN kind chirp_kind NOT NULL DEFAULT 'chirp',
+ADD COLUMN original_chirp_id UUID,
+ADD COLUMN rechirp_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN quote_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX chirps_original_chirp_id_idx ON chirps (original_chirp_id);
+CREATE UNIQUE INDEX chirps_user_id_rechirp_idx ON chirps (user_id, original_chirp_id)
+WHERE kind = 'rechirp';
+
+-- +goose Down
+DROP INDEX chirps_user_id_rechirp_idx;
+DROP INDEX chirps_original_chirp_id_idx;
+ALTER TABLE chirps
+DROP COLUMN quote_count,
+DROP COLUMN rechirp_count,
+DROP COLUMN original_chirp_id,
+DROP COLUMN kind;
+DROP TYPE chirp_kind;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TYPE moderation_action AS ENUM ('mask', 'reject', 'flag');
+
+CREATE TABLE moderation_words (
+    word TEXT PRIMARY KEY,
+    action moderation_action NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO moderation_words (word, action, created_at, updated_at)
+VALUES
+    ('kerfuffle', 'mask', NOW(), NOW()),
+    ('sharbert', 'mask', NOW(), NOW()),
+    ('fornax', 'mask', NOW(), NOW());
+
+CREATE TABLE moderation_flags (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP
+);
+
+CREATE INDEX moderation_flags_unreviewed_idx ON moderation_flags (created_at, id)
+WHERE reviewed_at IS NULL;
+
+-- +goose Down
+DROP TABLE moderation_flags;
+DROP TABLE moderation_words;
+DROP TYPE moderation_action;
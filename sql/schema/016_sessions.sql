@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE refresh_tokens ADD COLUMN session_started_at TIMESTAMP;
+ALTER TABLE refresh_tokens ADD COLUMN last_used_at TIMESTAMP;
+ALTER TABLE refresh_tokens ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE refresh_tokens ADD COLUMN ip_address TEXT NOT NULL DEFAULT '';
+UPDATE refresh_tokens SET session_started_at = created_at, last_used_at = updated_at;
+ALTER TABLE refresh_tokens ALTER COLUMN session_started_at SET NOT NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN last_used_at SET NOT NULL;
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+
+-- +goose Down
+DROP INDEX refresh_tokens_user_id_idx;
+ALTER TABLE refresh_tokens DROP COLUMN ip_address;
+ALTER TABLE refresh_tokens DROP COLUMN user_agent;
+ALTER TABLE refresh_tokens DROP COLUMN last_used_at;
+ALTER TABLE refresh_tokens DROP COLUMN session_started_at;
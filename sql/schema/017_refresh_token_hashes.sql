@@ -0,0 +1,12 @@
+-- +goose Up
+-- Only the SHA-256 digest of a refresh token is stored. Hashing the existing
+-- plaintext tokens in place keeps their sessions working.
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+UPDATE refresh_tokens SET
+    token_hash = encode(sha256(convert_to(token_hash, 'UTF8')), 'hex'),
+    replaced_by = encode(sha256(convert_to(replaced_by, 'UTF8')), 'hex');
+
+-- +goose Down
+-- Digests can't be turned back into tokens, so everyone has to log in again.
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE revoked_access_tokens (
+    jti TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX revoked_access_tokens_expires_at_idx ON revoked_access_tokens (expires_at);
+
+-- The access token issued together with each refresh token, so logging a
+-- session out can revoke its access tokens too.
+ALTER TABLE refresh_tokens ADD COLUMN access_token_jti TEXT;
+
+-- +goose Down
+ALTER TABLE refresh_tokens DROP COLUMN access_token_jti;
+DROP TABLE revoked_access_tokens;
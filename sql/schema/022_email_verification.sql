@@ -0,0 +1,26 @@
+-- +goose Up
+-- Emails are compared lowercased from now on. This fails if two accounts
+-- only differ by case, which has to be resolved by hand first.
+UPDATE users SET email = LOWER(TRIM(email));
+
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+-- Existing accounts keep posting; only new accounts and changed addresses
+-- have to be verified.
+UPDATE users SET email_verified_at = NOW();
+
+CREATE TABLE email_verification_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- The address being verified, which is not the user's current one while
+    -- they are changing it.
+    email TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX email_verification_tokens_user_id_idx ON email_verification_tokens (user_id);
+
+-- +goose Down
+DROP TABLE email_verification_tokens;
+ALTER TABLE users DROP COLUMN email_verified_at;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Failed login counts, keyed by account or client IP.
+CREATE TABLE login_failures (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    last_failure_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE login_lockouts (
+    id UUID PRIMARY KEY,
+    email TEXT NOT NULL,
+    -- NULL when nobody has the email; attackers guess those too.
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ip_address TEXT NOT NULL,
+    failures INTEGER NOT NULL,
+    locked_until TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP
+);
+
+CREATE INDEX login_lockouts_unreviewed_idx ON login_lockouts (created_at, id) WHERE reviewed_at IS NULL;
+
+-- +goose Down
+DROP TABLE login_lockouts;
+DROP TABLE login_failures;
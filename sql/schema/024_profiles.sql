@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN handle TEXT;
+-- Existing accounts get a placeholder handle they can change.
+UPDATE users SET handle = 'user_' || substr(md5(id::text), 1, 10);
+ALTER TABLE users ALTER COLUMN handle SET NOT NULL;
+-- Handles keep the case they were chosen in but are unique ignoring it.
+CREATE UNIQUE INDEX users_handle_idx ON users (LOWER(handle));
+
+ALTER TABLE users ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_media_id UUID REFERENCES media(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN avatar_media_id;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
+DROP INDEX users_handle_idx;
+ALTER TABLE users DROP COLUMN handle;
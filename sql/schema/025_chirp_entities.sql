@@ -0,0 +1,29 @@
+-- +goose Up
+-- Mentions and hashtags found in chirp bodies when they were posted or
+-- edited. start_index and end_index are character offsets into the body.
+CREATE TABLE chirp_mentions (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- The handle as written, which stays in the body if the user changes it.
+    handle TEXT NOT NULL,
+    start_index INTEGER NOT NULL,
+    end_index INTEGER NOT NULL,
+    PRIMARY KEY (chirp_id, start_index)
+);
+
+CREATE INDEX chirp_mentions_user_id_idx ON chirp_mentions (user_id);
+
+CREATE TABLE chirp_hashtags (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    -- Lowercase, see chirptext.ParseHashtag.
+    tag TEXT NOT NULL,
+    start_index INTEGER NOT NULL,
+    end_index INTEGER NOT NULL,
+    PRIMARY KEY (chirp_id, start_index)
+);
+
+CREATE INDEX chirp_hashtags_tag_idx ON chirp_hashtags (tag);
+
+-- +goose Down
+DROP TABLE chirp_hashtags;
+DROP TABLE chirp_mentions;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Login attempts count as failures as soon as they start, so parallel
+-- guesses can't all get past the throttle. The time of the failure before
+-- the latest one is what the attempt is judged by.
+ALTER TABLE login_failures ADD COLUMN previous_failure_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE login_failures DROP COLUMN previous_failure_at;
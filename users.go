@@ -1,28 +1,46 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/gooneraki/chirpy-go/internal/auth"
 	"github.com/gooneraki/chirpy-go/internal/database"
+	"github.com/gooneraki/chirpy-go/internal/mail"
 )
 
+// User is what users see about themselves: their public profile plus
+// private details.
 type User struct {
-	ID          uuid.UUID `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Email       string    `json:"email"`
-	Password    string    `json:"-"`
-	IsChirpyRed bool      `json:"is_chirpy_red"`
+	Profile
+	UpdatedAt time.Time `json:"updated_at"`
+	Email     string    `json:"email"`
+	Password  string    `json:"-"`
+	// EmailVerified is false until the user follows the link emailed to
+	// them. Until then they can't post.
+	EmailVerified bool `json:"email_verified"`
+}
+
+func databaseUserToUser(user database.User) User {
+	return User{
+		Profile:       databaseUserToProfile(user),
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+	}
 }
 
 func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		// Handle is optional; users without one get a placeholder they can
+		// change later.
+		Handle string `json:"handle"`
 	}
 	type response struct {
 		User
@@ -36,14 +54,47 @@ func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	hashedPass, err := auth.HashPassword(params.Password)
+	email, err := mail.NormalizeAddress(params.Email)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid email address", err)
+		return
+	}
+	_, err = cfg.db.GetUserByEmail(r.Context(), email)
+	if err == nil {
+		respondWithError(w, http.StatusConflict, "Email is already in use", nil)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check email", err)
+		return
+	}
+
+	handle := params.Handle
+	if handle == "" {
+		handle = defaultHandle()
+	} else {
+		err = cfg.checkHandleAvailable(r.Context(), uuid.Nil, handle)
+		if err != nil {
+			respondWithProfileValidationError(w, err)
+			return
+		}
+	}
+
+	err = cfg.passwordPolicy.Check(params.Password, email)
+	if err != nil {
+		respondWithPasswordPolicyError(w, err)
+		return
+	}
+
+	hashedPass, err := cfg.hashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "couldn't hash the password", err)
 		return
 	}
 
 	user, err := cfg.db.CreateUser(r.Context(), database.CreateUserParams{
-		Email:          params.Email,
+		Email:          email,
+		Handle:         handle,
 		HashedPassword: hashedPass,
 	})
 	if err != nil {
@@ -51,13 +102,14 @@ func (cfg *apiConfig) handlerUsersCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// The account exists either way; a failed email can be sent again
+	// through POST /api/users/verify-email/resend.
+	err = cfg.sendEmailVerification(r.Context(), user.ID, user.Email)
+	if err != nil {
+		log.Printf("Error sending verification email: %s", err)
+	}
+
 	respondWithJSON(w, http.StatusCreated, response{
-		User: User{
-			ID:          user.ID,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-			Email:       user.Email,
-			IsChirpyRed: user.IsChirpyRed,
-		},
+		User: databaseUserToUser(user),
 	})
 }
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gooneraki/chirpy-go/internal/auth"
+)
+
+// viewerID identifies the caller on endpoints that work without
+// authentication but personalise the response when a JWT is given. A missing
+// Authorization header is not an error; an invalid token is.
+func (cfg *apiConfig) viewerID(r *http.Request) (uuid.NullUUID, error) {
+	token, err := auth.GetBearerToken(r.Header)
+	if errors.Is(err, auth.ErrNoAuthHeaderIncluded) {
+		return uuid.NullUUID{}, nil
+	}
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	claims, err := cfg.validateJWT(r.Context(), token)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: claims.UserID, Valid: true}, nil
+}